@@ -0,0 +1,187 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+)
+
+// Addressing describes how the device obtains its network address.
+type Addressing struct {
+	DHCP    bool
+	Prefix  netip.Prefix // static address and subnet
+	Gateway netip.Addr   // static gateway
+	DNS     []netip.Addr // static DNS servers
+}
+
+// SetDHCP configures the device to obtain its address by DHCP. The
+// static settings are left as they are.
+func (d *DeviceData) SetDHCP() {
+	d.LanIPMode = true
+}
+
+// SetStatic configures the device with a static address and subnet, a
+// gateway, and up to two DNS servers. The gateway must be inside the
+// subnet, and neither it nor the address may be the network or
+// broadcast address of the subnet. DNS servers not given are cleared.
+func (d *DeviceData) SetStatic(prefix netip.Prefix, gateway netip.Addr, dns ...netip.Addr) error {
+	if !prefix.IsValid() || !prefix.Addr().Is4() {
+		return errors.New("Static address must be an IPv4 address and prefix")
+	}
+	if prefix.Bits() == 0 {
+		return errors.New("Subnet mask must not be 0.0.0.0")
+	}
+	addr := prefix.Addr()
+	if reservedAddr(prefix, addr) {
+		return errors.New("Address is the network or broadcast address of its subnet")
+	}
+	if !gateway.Is4() || !prefix.Contains(gateway) {
+		return errors.New("Gateway must be an IPv4 address inside the subnet")
+	}
+	if reservedAddr(prefix, gateway) {
+		return errors.New("Gateway is the network or broadcast address of its subnet")
+	}
+	if gateway == addr {
+		return errors.New("Gateway must not be the device address")
+	}
+	if len(dns) > 2 {
+		return errors.New("At most two DNS servers can be set")
+	}
+	for _, a := range dns {
+		if !a.Is4() {
+			return errors.New("DNS servers must be IPv4 addresses")
+		}
+	}
+
+	d.LanIPMode = false
+	d.LanNetworkAddress = ipFromAddr(addr)
+	d.LanSubnetMask = net.IP(net.CIDRMask(prefix.Bits(), 32))
+	d.LanGateway = ipFromAddr(gateway)
+	d.PrimaryDNS = make(net.IP, 4)
+	d.SecondaryDNS = make(net.IP, 4)
+	if len(dns) > 0 {
+		d.PrimaryDNS = ipFromAddr(dns[0])
+	}
+	if len(dns) > 1 {
+		d.SecondaryDNS = ipFromAddr(dns[1])
+	}
+	return nil
+}
+
+// Addressing interprets the network addressing fields. For DHCP only
+// the DHCP flag is set. For a static address, a subnet mask that is
+// not contiguous leaves Prefix invalid, and unset DNS servers are left
+// out.
+func (d *DeviceData) Addressing() Addressing {
+	if d.LanIPMode {
+		return Addressing{DHCP: true}
+	}
+	var a Addressing
+	if addr, ok := addrFromIP(d.LanNetworkAddress); ok {
+		if ones, bits := net.IPMask(d.LanSubnetMask.To4()).Size(); bits == 32 {
+			a.Prefix = netip.PrefixFrom(addr, ones)
+		}
+	}
+	a.Gateway, _ = addrFromIP(d.LanGateway)
+	for _, ip := range []net.IP{d.PrimaryDNS, d.SecondaryDNS} {
+		if addr, ok := addrFromIP(ip); ok && !addr.IsUnspecified() {
+			a.DNS = append(a.DNS, addr)
+		}
+	}
+	return a
+}
+
+// NetworkAddr returns the static network address. Like the other
+// address accessors, it returns an invalid netip.Addr if the value is
+// unset, so that it can be told apart from 0.0.0.0.
+func (d *DeviceData) NetworkAddr() netip.Addr { return optionalAddr(d.LanNetworkAddress) }
+
+// SubnetMaskAddr returns the static subnet mask.
+func (d *DeviceData) SubnetMaskAddr() netip.Addr { return optionalAddr(d.LanSubnetMask) }
+
+// GatewayAddr returns the static gateway address.
+func (d *DeviceData) GatewayAddr() netip.Addr { return optionalAddr(d.LanGateway) }
+
+// PrimaryDNSAddr returns the static primary DNS address.
+func (d *DeviceData) PrimaryDNSAddr() netip.Addr { return optionalAddr(d.PrimaryDNS) }
+
+// SecondaryDNSAddr returns the static secondary DNS address.
+func (d *DeviceData) SecondaryDNSAddr() netip.Addr { return optionalAddr(d.SecondaryDNS) }
+
+// ActiveServerAddr returns the address of the currently active server.
+func (d *DeviceData) ActiveServerAddr() netip.Addr { return optionalAddr(d.ActiveServerAddress) }
+
+// ServerAddr returns the address of the local SqueezeCenter server.
+func (d *DeviceData) ServerAddr() netip.Addr { return optionalAddr(d.SqueezeCenterAddress) }
+
+// AddrValue returns the named IP address configuration value of d, as
+// returned by its accessor, such as NetworkAddr for LanNetworkAddress.
+func (d *DeviceData) AddrValue(name string) (netip.Addr, error) {
+	f, ok := LookupField(name)
+	if !ok {
+		return netip.Addr{}, errors.New("Unknown field " + name)
+	}
+	accessors := map[string]func() netip.Addr{
+		"LanNetworkAddress":    d.NetworkAddr,
+		"LanSubnetMask":        d.SubnetMaskAddr,
+		"LanGateway":           d.GatewayAddr,
+		"PrimaryDNS":           d.PrimaryDNSAddr,
+		"SecondaryDNS":         d.SecondaryDNSAddr,
+		"ActiveServerAddress":  d.ActiveServerAddr,
+		"SqueezeCenterAddress": d.ServerAddr,
+	}
+	get, ok := accessors[f.Name]
+	if !ok {
+		return netip.Addr{}, errors.New(f.Name + " is not an IP address")
+	}
+	return get(), nil
+}
+
+// SetAddr stores an IPv4 address as the named configuration value of d.
+func (d *DeviceData) SetAddr(name string, a netip.Addr) error {
+	if !a.Is4() {
+		return errors.New("Not an IPv4 address")
+	}
+	return d.Set(name, a.String())
+}
+
+// reservedAddr reports whether a is the network or broadcast address of
+// p. Subnets of /31 and /32 have neither.
+func reservedAddr(p netip.Prefix, a netip.Addr) bool {
+	if p.Bits() >= 31 {
+		return false
+	}
+	return a == p.Masked().Addr() || a == lastAddr(p)
+}
+
+// lastAddr returns the broadcast address of an IPv4 prefix.
+func lastAddr(p netip.Prefix) netip.Addr {
+	a := p.Masked().Addr().As4()
+	mask := net.CIDRMask(p.Bits(), 32)
+	for i := range a {
+		a[i] |= ^mask[i]
+	}
+	return netip.AddrFrom4(a)
+}
+
+// ipFromAddr returns an IPv4 address as a 4-byte net.IP.
+func ipFromAddr(a netip.Addr) net.IP {
+	b := a.As4()
+	return net.IP(b[:])
+}
+
+// optionalAddr returns a net.IP as an IPv4 netip.Addr, or an invalid
+// netip.Addr if it is unset.
+func optionalAddr(ip net.IP) netip.Addr {
+	a, _ := addrFromIP(ip)
+	return a
+}
+
+// addrFromIP returns a net.IP as an IPv4 netip.Addr.
+func addrFromIP(ip net.IP) (netip.Addr, bool) {
+	return netip.AddrFromSlice(ip.To4())
+}
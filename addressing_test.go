@@ -0,0 +1,98 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestSetStatic(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		gateway string
+		dns     []string
+		ok      bool
+	}{
+		{"valid", "192.168.1.20/24", "192.168.1.1", []string{"192.168.1.1", "8.8.8.8"}, true},
+		{"point to point", "10.0.0.0/31", "10.0.0.1", nil, true},
+		{"zero mask", "192.168.1.20/0", "192.168.1.1", nil, false},
+		{"ipv6", "fd00::20/64", "fd00::1", nil, false},
+		{"network address", "192.168.1.0/24", "192.168.1.1", nil, false},
+		{"broadcast address", "192.168.1.255/24", "192.168.1.1", nil, false},
+		{"gateway outside subnet", "192.168.1.20/24", "192.168.2.1", nil, false},
+		{"gateway is network address", "192.168.1.20/24", "192.168.1.0", nil, false},
+		{"gateway is broadcast address", "192.168.1.20/24", "192.168.1.255", nil, false},
+		{"gateway is device", "192.168.1.20/24", "192.168.1.20", nil, false},
+		{"too many dns", "192.168.1.20/24", "192.168.1.1", []string{"1.1.1.1", "8.8.8.8", "9.9.9.9"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dns []netip.Addr
+			for _, s := range tt.dns {
+				dns = append(dns, netip.MustParseAddr(s))
+			}
+			d := DeviceData{LanIPMode: true}
+			err := d.SetStatic(netip.MustParsePrefix(tt.prefix), netip.MustParseAddr(tt.gateway), dns...)
+			if (err == nil) != tt.ok {
+				t.Fatalf("SetStatic() error = %v, want ok %v", err, tt.ok)
+			}
+			if !tt.ok {
+				if !d.LanIPMode {
+					t.Error("configuration changed on error")
+				}
+				return
+			}
+			a := d.Addressing()
+			if a.DHCP || a.Prefix != netip.MustParsePrefix(tt.prefix) || a.Gateway != netip.MustParseAddr(tt.gateway) {
+				t.Errorf("Addressing() = %+v", a)
+			}
+			if len(a.DNS) != len(tt.dns) {
+				t.Errorf("DNS = %v, want %v", a.DNS, tt.dns)
+			}
+		})
+	}
+}
+
+func TestSetStaticDoesNotAlias(t *testing.T) {
+	var d DeviceData
+	if err := d.SetStatic(netip.MustParsePrefix("192.168.1.20/24"), netip.MustParseAddr("192.168.1.1")); err != nil {
+		t.Fatal(err)
+	}
+	d.PrimaryDNS[0] = 1
+	if !net.IPv4zero.Equal(net.IPv4(0, 0, 0, 0)) || d.SecondaryDNS[0] != 0 {
+		t.Error("cleared DNS servers share memory")
+	}
+}
+
+func TestAddrValue(t *testing.T) {
+	d := DeviceData{
+		LanNetworkAddress: net.IPv4(192, 168, 1, 20),
+		LanGateway:        make(net.IP, 4),
+	}
+	tests := []struct {
+		name string
+		want netip.Addr
+	}{
+		{"LanNetworkAddress", netip.MustParseAddr("192.168.1.20")},
+		{"LanGateway", netip.IPv4Unspecified()},
+		{"PrimaryDNS", netip.Addr{}}, // unset
+		{"squeezecenteraddress", netip.Addr{}},
+	}
+	for _, tt := range tests {
+		got, err := d.AddrValue(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("AddrValue(%s) = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := d.AddrValue("Hostname"); err == nil {
+		t.Error("AddrValue(Hostname) succeeded")
+	}
+	if d.NetworkAddr() != netip.MustParseAddr("192.168.1.20") || d.PrimaryDNSAddr().IsValid() {
+		t.Error("accessors disagree with AddrValue")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupVersion is the archive format written by Backup.
+const BackupVersion = 1
+
+// ErrOtherDevice is returned by Restore when the backup was taken from
+// a device with a different hardware address.
+var ErrOtherDevice = errors.New("Backup was taken from a different device")
+
+// Backup is an archive of the configuration of a device.
+type Backup struct {
+	Version     int            `json:"version"`
+	Created     time.Time      `json:"created"`
+	MacAddr     string         `json:"mac"`
+	ID          uint           `json:"id"`
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	HardwareRev uint           `json:"hardware_rev"`
+	FirmwareRev uint           `json:"firmware_rev"`
+	UUID        string         `json:"uuid,omitempty"`
+	Data        DeviceData     `json:"data"`
+	Unknown     map[int][]byte `json:"unknown,omitempty"` // raw values keyed by offset
+}
+
+// Backup retrieves the configuration of the device and returns it as
+// an archive. The ranges between DeviceData fields are read as well and
+// included as raw values, along with any other values already kept in
+// Unknown, e.g. by ReadRaw.
+func (s *Sb) Backup(iface *net.Interface) (*Backup, error) {
+	if err := s.GetData(iface); err != nil {
+		return nil, err
+	}
+	if err := s.readUnknown(iface); err != nil {
+		return nil, err
+	}
+	return s.archive(), nil
+}
+
+// archive returns the configuration last read from the device as an
+// archive.
+func (s *Sb) archive() *Backup {
+	b := &Backup{
+		Version:     BackupVersion,
+		Created:     time.Now().UTC(),
+		MacAddr:     s.MacAddr.String(),
+		ID:          s.ID,
+		Type:        s.Type,
+		Name:        s.Name,
+		HardwareRev: s.HardwareRev,
+		FirmwareRev: s.FirmwareRev,
+		UUID:        s.UUID,
+		Data:        s.Data,
+	}
+	if len(s.Unknown) > 0 {
+		b.Unknown = make(map[int][]byte, len(s.Unknown))
+		for offset, v := range s.Unknown {
+			b.Unknown[offset] = append([]byte(nil), v...)
+		}
+	}
+	return b
+}
+
+// Encode returns the archive as JSON.
+func (b *Backup) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// DecodeBackup parses an archive returned by Encode.
+func DecodeBackup(data []byte) (*Backup, error) {
+	var b Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if b.Version != BackupVersion {
+		return nil, fmt.Errorf("Unsupported backup version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// RestoreOption changes how Restore applies a backup.
+type RestoreOption func(*restoreOptions)
+
+type restoreOptions struct {
+	allowOtherDevice bool
+}
+
+// AllowOtherDevice restores a backup taken from a different device.
+func AllowOtherDevice() RestoreOption {
+	return func(o *restoreOptions) {
+		o.allowOtherDevice = true
+	}
+}
+
+// Restore saves every writable value of the backup, and the raw values
+// at unknown offsets, to the device. The values are not validated as
+// they were read from a device. Raw values overlapping a DeviceData
+// field are skipped, so they cannot overwrite read only values.
+// ErrOtherDevice is returned if the backup was taken from another
+// device, unless AllowOtherDevice is given, and ErrPartialSave if the
+// device does not save every value.
+func (s *Sb) Restore(iface *net.Interface, b *Backup, opts ...RestoreOption) error {
+	if s.MacAddr == nil {
+		return errors.New("Hardware address required")
+	}
+	if b.Version != BackupVersion {
+		return fmt.Errorf("Unsupported backup version %d", b.Version)
+	}
+
+	var o restoreOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.allowOtherDevice && !strings.EqualFold(b.MacAddr, s.MacAddr.String()) {
+		return ErrOtherDevice
+	}
+
+	data := b.Data
+	for _, f := range Fields {
+		if f.ReadOnly {
+			data.decodeField(f.Offset, s.Data.encodeField(f.Offset))
+		}
+	}
+	s.Data = data
+	if err := s.SaveData(iface, SkipValidation(), FullWrite()); err != nil {
+		return err
+	}
+
+	offsets := make([]int, 0, len(b.Unknown))
+	for offset, v := range b.Unknown {
+		if !knownRange(offset, len(v)) {
+			offsets = append(offsets, offset)
+		}
+	}
+	sort.Ints(offsets)
+	for _, offset := range offsets {
+		if err := s.WriteRaw(iface, offset, b.Unknown[offset]); err != nil {
+			return fmt.Errorf("Restoring offset %d: %w", offset, err)
+		}
+	}
+	return nil
+}
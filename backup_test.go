@@ -0,0 +1,63 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	mac, _ := net.ParseMAC("00:04:20:12:34:56")
+	// A device reporting values that have no name
+	s := Sb{
+		MacAddr:     mac,
+		ID:          7,
+		Type:        "squeezebox",
+		Name:        "Kitchen",
+		HardwareRev: 2,
+		FirmwareRev: 77,
+		Data: DeviceData{
+			LanIPMode:         true,
+			Hostname:          "kitchen",
+			Interface:         Link(2),
+			WirelessMode:      WirelessMode(3),
+			WirelessRegion:    Region(0),
+			WirelessWPACipher: WPACipher(9),
+			WirelessWPAMode:   WPAMode(4),
+			WirelessWEPKey1:   WEPKey("abcde"),
+		},
+		Unknown: map[int][]byte{0: {1, 2, 3, 4}, 116: {0xff}},
+	}
+
+	b, err := s.archive().Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, err := DecodeBackup(b)
+	if err != nil {
+		t.Fatalf("DecodeBackup: %v\n%s", err, b)
+	}
+
+	want, _ := s.Data.MarshalBinary()
+	image, _ := got.Data.MarshalBinary()
+	if !bytes.Equal(image, want) {
+		t.Errorf("configuration changed in the round trip\n%s", b)
+	}
+	if got.MacAddr != mac.String() || got.ID != s.ID || got.Type != s.Type || got.FirmwareRev != s.FirmwareRev {
+		t.Errorf("device details changed: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Unknown, s.Unknown) {
+		t.Errorf("Unknown = %v, want %v", got.Unknown, s.Unknown)
+	}
+}
+
+func TestDecodeBackupVersion(t *testing.T) {
+	if _, err := DecodeBackup([]byte(`{"version": 2}`)); err == nil {
+		t.Error("DecodeBackup accepted an unsupported version")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/jcrummy/gosqueeze"
+)
+
+func backup(device *gosqueeze.Sb, iface *net.Interface, filename string) {
+	b, err := device.Backup(iface)
+	if err != nil {
+		fmt.Printf("Error retrieving device data: %s\n", err.Error())
+		return
+	}
+	data, err := b.Encode()
+	if err != nil {
+		fmt.Printf("Error encoding backup: %s\n", err.Error())
+		return
+	}
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		fmt.Printf("Error writing backup: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Backup written to %s (%d unknown offsets).\n", filename, len(b.Unknown))
+}
+
+// restore applies the backup in arg, a file name optionally followed by
+// 'force' to allow restoring a backup of another device.
+func restore(device *gosqueeze.Sb, iface *net.Interface, arg string) {
+	var opts []gosqueeze.RestoreOption
+	if name := strings.TrimSuffix(arg, " force"); name != arg {
+		arg = strings.TrimSpace(name)
+		opts = append(opts, gosqueeze.AllowOtherDevice())
+	}
+
+	data, err := os.ReadFile(arg)
+	if err != nil {
+		fmt.Printf("Error reading backup: %s\n", err.Error())
+		return
+	}
+	b, err := gosqueeze.DecodeBackup(data)
+	if err != nil {
+		fmt.Printf("Error decoding backup: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Restoring backup of %s taken %s.\n", b.MacAddr, b.Created.Local().Format("2006-01-02 15:04:05"))
+
+	err = device.Restore(iface, b, opts...)
+	if errors.Is(err, gosqueeze.ErrOtherDevice) {
+		fmt.Printf("Not restored, the backup was taken from %s. Add 'force' to restore it anyway.\n", b.MacAddr)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error restoring backup: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/jcrummy/gosqueeze"
+)
+
+// fileArg returns the file name given after the device index of cmd.
+func fileArg(s string, cmd string) (string, bool) {
+	fields := strings.SplitN(s, " ", 3)
+	if len(fields) < 3 || strings.TrimSpace(fields[2]) == "" {
+		fmt.Printf("No file specified. Use '%s 0 sb.yaml' to %s the first device.\n", cmd, cmd)
+		return "", false
+	}
+	return strings.TrimSpace(fields[2]), true
+}
+
+func exportConfig(device *gosqueeze.Sb, iface *net.Interface, filename string) {
+	if err := device.GetData(iface); err != nil {
+		fmt.Printf("Error retrieving device data: %s\n", err.Error())
+		return
+	}
+	b, err := device.Data.ExportConfig(gosqueeze.ConfigFormatFor(filename))
+	if err != nil {
+		fmt.Printf("Error encoding configuration: %s\n", err.Error())
+		return
+	}
+	if err := os.WriteFile(filename, b, 0600); err != nil {
+		fmt.Printf("Error writing configuration: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Configuration written to %s.\n", filename)
+}
+
+func importConfig(device *gosqueeze.Sb, iface *net.Interface, filename string) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading configuration: %s\n", err.Error())
+		return
+	}
+	if err := device.GetData(iface); err != nil {
+		fmt.Printf("Error retrieving device data: %s\n", err.Error())
+		return
+	}
+
+	data := device.Data
+	if err := data.ImportConfig(b, gosqueeze.ConfigFormatFor(filename)); err != nil {
+		fmt.Printf("Error decoding configuration: %s\n", err.Error())
+		return
+	}
+	if verr, ok := data.ValidateFields(device.SupportedFields()).(gosqueeze.ValidationError); ok {
+		fmt.Println("Not imported, the following values are invalid:")
+		for _, fe := range verr {
+			fmt.Printf("  %s\n", fe.Error())
+		}
+		return
+	}
+
+	device.Data = data
+	if len(device.Modified()) == 0 {
+		fmt.Println("No changes to save.")
+		return
+	}
+	if err := device.SaveData(iface); err != nil {
+		fmt.Printf("Error saving data: %s\n", err.Error())
+		return
+	}
+	fmt.Println("Successfully set data.")
+}
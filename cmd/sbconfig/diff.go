@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/jcrummy/gosqueeze"
+)
+
+// diff compares the device with another device, if arg is a device
+// index, or with a configuration file.
+func diff(device *gosqueeze.Sb, iface *net.Interface, arg string) {
+	from, ok := deviceData(device, iface)
+	if !ok {
+		return
+	}
+
+	var to gosqueeze.DeviceData
+	if i, err := strconv.Atoi(arg); err == nil {
+		if i < 0 || i > len(sbs)-1 {
+			fmt.Println("No such device.")
+			return
+		}
+		if to, ok = deviceData(&sbs[i], iface); !ok {
+			return
+		}
+	} else {
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Printf("Error reading configuration: %s\n", err.Error())
+			return
+		}
+		to = from
+		if err := to.ImportConfig(b, gosqueeze.ConfigFormatFor(arg)); err != nil {
+			fmt.Printf("Error decoding configuration: %s\n", err.Error())
+			return
+		}
+	}
+
+	diffs := from.Diff(&to, device.SupportedFields())
+	if len(diffs) == 0 {
+		fmt.Println("No differences.")
+		return
+	}
+	for _, d := range diffs {
+		fmt.Printf("  %s\n", d.String())
+	}
+}
+
+// deviceData returns the values last read from the device, reading
+// them if necessary. Unsaved changes to Data are not included.
+func deviceData(device *gosqueeze.Sb, iface *net.Interface) (gosqueeze.DeviceData, bool) {
+	if d, ok := device.Fetched(); ok {
+		return d, true
+	}
+	if err := device.GetData(iface); err != nil {
+		fmt.Printf("Error retrieving device data: %s\n", err.Error())
+		return gosqueeze.DeviceData{}, false
+	}
+	return device.Fetched()
+}
@@ -11,7 +11,7 @@ var sbs []gosqueeze.Sb
 
 func discover(iface *net.Interface) {
 	var err error
-	sbs, err = gosqueeze.Discover(iface)
+	sbs, err = gosqueeze.Discover(iface, discoverOptions...)
 	if err != nil {
 		fmt.Printf("Error finding devices: %s\n", err.Error())
 	}
@@ -22,10 +22,23 @@ func discover(iface *net.Interface) {
 			fmt.Printf("Error retrieving IP address: %s\n", err.Error())
 		}
 		err = sbs[i].GetData(iface)
-		if err != nil {
+		if err == gosqueeze.ErrCredentials {
+			fmt.Println("Device is password protected. Use 'password' when configuring it.")
+		} else if err != nil {
 			fmt.Printf("Error retrieving device data: %s\n", err.Error())
 		}
-		fmt.Printf("  [%02d] %+v at %+v\n", i, sbs[i].MacAddr, sbs[i].IPAddr)
+		fmt.Printf("  [%02d] %+v at %+v - %s, firmware %s", i, sbs[i].MacAddr, sbs[i].IPAddr,
+			sbs[i].HardwareVersion(), sbs[i].FirmwareVersion())
+		if sbs[i].OutdatedFirmware() {
+			fmt.Print(" (outdated)")
+		}
+		fmt.Println()
+		if sbs[i].Model().Name == gosqueeze.UnknownModel.Name {
+			fmt.Printf("       ! Unrecognised model (ID %d), all settings are shown whether or not they apply\n", sbs[i].ID)
+		}
+		if !sbs[i].Status.Healthy() {
+			fmt.Printf("       ! %s\n", sbs[i].Status.Description())
+		}
 	}
 
 	return
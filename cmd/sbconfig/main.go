@@ -1,16 +1,39 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/jcrummy/gosqueeze"
 	"github.com/jcrummy/gosqueeze/cmd/sbconfig/sb"
 )
 
+// discoverOptions are applied to every device found by discover.
+var discoverOptions []gosqueeze.DiscoverOption
+
 func main() {
+	minFirmware := flag.String("min-firmware", "", "flag devices running older firmware: 'latest', or revisions by model (ex: 'receiver=77,boom=57')")
+	password := flag.String("password", "", "password used to access devices")
+	flag.Parse()
+	if *password != "" {
+		creds, err := gosqueeze.NewCredentials(*password)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		discoverOptions = append(discoverOptions, gosqueeze.WithCredentials(creds))
+	}
+	if *minFirmware != "" {
+		if err := setMinimumFirmware(*minFirmware); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
 	selectInterface()
 	discover(selectedInterface())
 
@@ -25,10 +48,16 @@ func main() {
 
 func completer(d prompt.Document) []prompt.Suggest {
 	s := []prompt.Suggest{
+		{Text: "backup", Description: "Save a full backup of a device to a file (ex: 'backup 0 sb.backup')"},
 		{Text: "configure", Description: "Configure selected device (ex: 'configure 0')"},
+		{Text: "diff", Description: "Compare a device with another device or a configuration file (ex: 'diff 0 1')"},
 		{Text: "discover", Description: "Search network for devices"},
 		{Text: "exit", Description: "Exit program"},
+		{Text: "export", Description: "Write device configuration to a JSON or YAML file (ex: 'export 0 sb.yaml')"},
+		{Text: "import", Description: "Apply a JSON or YAML configuration file to a device (ex: 'import 0 sb.yaml')"},
 		{Text: "interface", Description: "Select a new interface to use"},
+		{Text: "probe", Description: "Map the configuration offsets of a device (ex: 'probe 0' or 'probe 0 0 512 64')"},
+		{Text: "restore", Description: "Restore a backup to a device (ex: 'restore 0 sb.backup')"},
 	}
 	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
 }
@@ -50,22 +79,114 @@ func executor(s string) {
 		selectInterface()
 
 	case "configure":
-		fields := strings.Split(s, " ")
-		if len(fields) < 2 {
-			fmt.Println("No device specified. Use 'configure 0' to configure the first device.")
+		deviceIndex, ok := deviceArg(s, "configure")
+		if !ok {
 			return
 		}
-		deviceIndex, err := strconv.Atoi(fields[1])
-		if err != nil {
-			fmt.Println("Not a number. Use 'configure 0' to configure the first device.")
+		fmt.Printf("Configuring device #%d (%+v).\n", deviceIndex, sbs[deviceIndex].MacAddr)
+		sb.Configure(&sbs[deviceIndex], selectedInterface())
+
+	case "diff":
+		deviceIndex, ok := deviceArg(s, cmd)
+		if !ok {
 			return
 		}
-		if (deviceIndex > len(sbs)-1) || (deviceIndex < 0) {
-			fmt.Println("No such device. Use 'configure 0' to configure the first device.")
+		other, ok := fileArg(s, cmd)
+		if !ok {
 			return
 		}
-		fmt.Printf("Configuring device #%d (%+v).\n", deviceIndex, sbs[deviceIndex].MacAddr)
-		sb.Configure(&sbs[deviceIndex], selectedInterface())
+		diff(&sbs[deviceIndex], selectedInterface(), other)
+
+	case "backup", "restore":
+		deviceIndex, ok := deviceArg(s, cmd)
+		if !ok {
+			return
+		}
+		filename, ok := fileArg(s, cmd)
+		if !ok {
+			return
+		}
+		if cmd == "backup" {
+			backup(&sbs[deviceIndex], selectedInterface(), filename)
+		} else {
+			restore(&sbs[deviceIndex], selectedInterface(), filename)
+		}
+
+	case "export", "import":
+		deviceIndex, ok := deviceArg(s, cmd)
+		if !ok {
+			return
+		}
+		filename, ok := fileArg(s, cmd)
+		if !ok {
+			return
+		}
+		if cmd == "export" {
+			exportConfig(&sbs[deviceIndex], selectedInterface(), filename)
+		} else {
+			importConfig(&sbs[deviceIndex], selectedInterface(), filename)
+		}
+
+	case "probe":
+		deviceIndex, ok := deviceArg(s, "probe")
+		if !ok {
+			return
+		}
+		probe(&sbs[deviceIndex], selectedInterface(), strings.Fields(s)[2:])
 	}
 	return
 }
+
+// deviceArg returns the device index given as the argument of cmd.
+func deviceArg(s string, cmd string) (int, bool) {
+	fields := strings.Split(s, " ")
+	if len(fields) < 2 {
+		fmt.Printf("No device specified. Use '%s 0' to %s the first device.\n", cmd, cmd)
+		return 0, false
+	}
+	deviceIndex, err := strconv.Atoi(fields[1])
+	if err != nil {
+		fmt.Printf("Not a number. Use '%s 0' to %s the first device.\n", cmd, cmd)
+		return 0, false
+	}
+	if (deviceIndex > len(sbs)-1) || (deviceIndex < 0) {
+		fmt.Printf("No such device. Use '%s 0' to %s the first device.\n", cmd, cmd)
+		return 0, false
+	}
+	return deviceIndex, true
+}
+
+// setMinimumFirmware sets the minimum firmware revision of each model
+// from spec, either "latest" for the latest known release of every
+// model, or a comma separated list of model=revision pairs. Models are
+// given by firmware image name, such as "receiver", or device ID.
+func setMinimumFirmware(spec string) error {
+	if spec == "latest" {
+		for _, m := range gosqueeze.Models {
+			if fw, ok := gosqueeze.LatestFirmware(m.ID); ok {
+				gosqueeze.MinimumFirmware[m.ID] = fw.Revision
+			}
+		}
+		return nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		name, rev, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("Invalid minimum firmware %q, use model=revision", pair)
+		}
+		revision, err := strconv.ParseUint(rev, 10, 16)
+		if err != nil {
+			return fmt.Errorf("Invalid firmware revision %q", rev)
+		}
+		var id uint
+		if m, ok := gosqueeze.LookupModel(name); ok {
+			id = m.ID
+		} else if n, err := strconv.ParseUint(name, 10, 16); err == nil {
+			id = uint(n)
+		} else {
+			return fmt.Errorf("Unknown model %q", name)
+		}
+		gosqueeze.MinimumFirmware[id] = uint(revision)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/jcrummy/gosqueeze"
+)
+
+// Default offsets probed, and the number requested at a time
+const (
+	probeStart = 0
+	probeEnd   = 0xFFFF
+	probeChunk = 64
+)
+
+// probeRange is the offsets probed, from start up to end, requested
+// chunk at a time.
+type probeRange struct {
+	start, end, chunk int
+}
+
+// probeSnapshot is a probe of a device along with the range probed.
+type probeSnapshot struct {
+	probeRange
+	snap gosqueeze.Snapshot
+}
+
+// snapshots holds the last probe of each device, keyed by hardware address.
+var snapshots = make(map[string]probeSnapshot)
+
+// parseProbeRange parses the optional start, end and chunk arguments
+// following the device index of the probe command.
+func parseProbeRange(args []string) (probeRange, error) {
+	r := probeRange{start: probeStart, end: probeEnd, chunk: probeChunk}
+	if len(args) > 3 {
+		return r, errors.New("Too many arguments")
+	}
+	if len(args) == 1 {
+		return r, errors.New("An end offset is required with a start offset")
+	}
+	targets := []*int{&r.start, &r.end, &r.chunk}
+	for i, arg := range args {
+		v, err := strconv.ParseInt(arg, 0, 32)
+		if err != nil {
+			return r, fmt.Errorf("%s is not a number", arg)
+		}
+		*targets[i] = int(v)
+	}
+	return r, nil
+}
+
+func probe(device *gosqueeze.Sb, iface *net.Interface, args []string) {
+	r, err := parseProbeRange(args)
+	if err != nil {
+		fmt.Printf("%s. Use 'probe 0 [start end [chunk]]'.\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Probing offsets %d to %d, %d at a time...\n", r.start, r.end-1, r.chunk)
+	snap, err := device.Probe(iface, r.start, r.end, r.chunk, gosqueeze.ProbeProgress(func(offset int, answered int) {
+		fmt.Printf("\r  offset %d of %d, %d answered", min(offset, r.end), r.end, answered)
+	}))
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("Error probing device: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Device answered %d offsets: %s\n", len(snap), offsetRanges(snap.Offsets()))
+
+	key := device.MacAddr.String()
+	before, ok := snapshots[key]
+	snapshots[key] = probeSnapshot{probeRange: r, snap: snap}
+	if !ok || before.start != r.start || before.end != r.end {
+		fmt.Println("Snapshot taken. Change a setting on the device and probe the same offsets again to see what changed.")
+		return
+	}
+
+	changes := before.snap.Diff(snap)
+	if len(changes) == 0 {
+		fmt.Println("No changes since the last probe.")
+		return
+	}
+	fmt.Println("Changes since the last probe:")
+	for _, c := range changes {
+		name := "unknown"
+		if f, ok := gosqueeze.FieldAt(c.Offset); ok {
+			name = fmt.Sprintf("%s+%d", f.Name, c.Offset-f.Offset)
+		}
+		fmt.Printf("  [%d] %s: %s -> %s\n", c.Offset, name, probeValue(c.Before, c.BeforeAnswered), probeValue(c.After, c.AfterAnswered))
+	}
+}
+
+func probeValue(v byte, answered bool) string {
+	if !answered {
+		return "--"
+	}
+	return fmt.Sprintf("%02x", v)
+}
+
+// offsetRanges formats sorted offsets as a list of ranges.
+func offsetRanges(offsets []int) string {
+	var ranges []string
+	for i := 0; i < len(offsets); {
+		j := i
+		for j+1 < len(offsets) && offsets[j+1] == offsets[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("%d", offsets[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", offsets[i], offsets[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
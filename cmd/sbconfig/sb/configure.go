@@ -1,8 +1,10 @@
 package sb
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"os"
 	"strings"
 
 	"github.com/jcrummy/gosqueeze"
@@ -17,7 +19,7 @@ func Configure(device *gosqueeze.Sb, iface *net.Interface) {
 		iface:  iface,
 	}
 	fmt.Println("Use Ctrl-D to exit configuration mode.")
-	t := prompt.New(c.executor, configureCompleter,
+	t := prompt.New(c.executor, c.completer,
 		prompt.OptionTitle(fmt.Sprintf("sbconfig: Configuring %+v", c.device.MacAddr)),
 		prompt.OptionPrefix("C>> "),
 	)
@@ -30,38 +32,21 @@ type configurator struct {
 	iface  *net.Interface
 }
 
-func configureCompleter(d prompt.Document) []prompt.Suggest {
+func (c *configurator) completer(d prompt.Document) []prompt.Suggest {
 	s := []prompt.Suggest{
 		{Text: "show", Description: "Show current settings"},
 		//{Text: "exit", Description: "Exit program"},
 		{Text: "set", Description: "Set a particular value"},
-		{Text: "save", Description: "Save current values to device"},
-	}
-	setpoints := []prompt.Suggest{
-		{Text: "LanIPMode", Description: "False = Static IP, True = DHCP"},
-		{Text: "LanNetworkAddress", Description: "Static IP address"},
-		{Text: "LanSubnetMask", Description: "Static subnet mask"},
-		{Text: "LanGateway", Description: "Static gateway address"},
-		{Text: "Hostname", Description: "Device hostname"},
-		{Text: "Bridging", Description: "True = use device as wireless bridge"},
-		{Text: "Interface", Description: "0 = Use wireless link, 1 = Use wired link"},
-		{Text: "PrimaryDNS", Description: "Static primary DNS address"},
-		{Text: "SecondaryDNS", Description: "Static secondary DNS address"},
-		{Text: "SqueezeCenterAddress", Description: "IP address of local Squeezecenter server"},
-		{Text: "Wireless Mode", Description: "0 = Infrastructure, 1 = Ad Hoc"},
-		{Text: "WirelessSSID", Description: "SSID of WiFi access point to connect to"},
-		{Text: "WirelessChannel", Description: "WiFi channel, 0 for automatic"},
-		{Text: "WirelessRegion", Description: "4 US, 6 CA, 7 AU, 13 FR, 14 EU, 16 JP, 21 TW, 23 CH"},
-		{Text: "WirelessKeylen", Description: "Length of wireless key (0 = 64-bit, 1 = 128-bit)"},
-		{Text: "WirelessWEPKey0", Description: "WEP key 0 - in hex"},
-		{Text: "WirelessWEPKey1", Description: "WEP key 1 - in hex"},
-		{Text: "WirelessWEPKey2", Description: "WEP key 2 - in hex"},
-		{Text: "WirelessWEPKey3", Description: "WEP key 3 - in hex"},
-		{Text: "WirelessWEPOn", Description: "0 = Wep Off, 1 = Wep On"},
-		{Text: "WirelessWPACipher", Description: "1 = TKIP, 2 = AES, 3 = TKIP & AES"},
-		{Text: "WirelessWPAMode", Description: "1 = WPA, 2 = WPA2"},
-		{Text: "WirelessWPAOn", Description: "0 = WPA Off, 1 = WPA On"},
-		{Text: "WirelessWPAPSK", Description: "WPA Public Shared Key"},
+		{Text: "password", Description: "Set the password used to access the device"},
+		{Text: "diff", Description: "Show unsaved changes, or compare the device with a configuration file"},
+		{Text: "save", Description: "Save changed values to device (options: full, force, verify)"},
+	}
+	var setpoints []prompt.Suggest
+	for _, f := range c.device.SupportedFields() {
+		if f.ReadOnly {
+			continue
+		}
+		setpoints = append(setpoints, prompt.Suggest{Text: f.Name, Description: f.Description})
 	}
 	cmds := strings.Split(d.Text, " ")
 	if cmds[0] == "set" {
@@ -74,32 +59,26 @@ func configureCompleter(d prompt.Document) []prompt.Suggest {
 }
 
 func (c *configurator) showValues() {
-	fmt.Printf("LanIPMode: %+v\n", c.device.Data.LanIPMode)
-	fmt.Printf("LanNetworkAddress: %+v\n", c.device.Data.LanNetworkAddress)
-	fmt.Printf("LanSubnetMask: %+v\n", c.device.Data.LanSubnetMask)
-	fmt.Printf("LanGateway: %+v\n", c.device.Data.LanGateway)
-	fmt.Printf("Hostname: %+v\n", c.device.Data.Hostname)
-	fmt.Printf("Bridging: %+v\n", c.device.Data.Bridging)
-	fmt.Printf("Interface: %+v\n", c.device.Data.Interface)
-	fmt.Printf("PrimaryDNS: %+v\n", c.device.Data.PrimaryDNS)
-	fmt.Printf("SecondaryDNS: %+v\n", c.device.Data.SecondaryDNS)
-	fmt.Printf("ActiveServerAddress: %+v\n", c.device.Data.ActiveServerAddress)
-	fmt.Printf("SqueezeCenterAddress: %+v\n", c.device.Data.SqueezeCenterAddress)
-	fmt.Printf("SqueezeCenterName: %+v\n", c.device.Data.SqueezeCenterName)
-	fmt.Printf("WirelessMode: %+v\n", c.device.Data.WirelessMode)
-	fmt.Printf("WirelessSSID: %+v\n", c.device.Data.WirelessSSID)
-	fmt.Printf("WirelessChannel: %+v\n", c.device.Data.WirelessChannel)
-	fmt.Printf("WirelessRegion: %+v\n", c.device.Data.WirelessRegion)
-	fmt.Printf("WirelessKeylen: %+v\n", c.device.Data.WirelessKeylen)
-	fmt.Printf("WirelessWEPKey0: %+v\n", c.device.Data.WirelessWEPKey0)
-	fmt.Printf("WirelessWEPKey1: %+v\n", c.device.Data.WirelessWEPKey1)
-	fmt.Printf("WirelessWEPKey2: %+v\n", c.device.Data.WirelessWEPKey2)
-	fmt.Printf("WirelessWEPKey3: %+v\n", c.device.Data.WirelessWEPKey3)
-	fmt.Printf("WirelessWEPOn: %+v\n", c.device.Data.WirelessWEPOn)
-	fmt.Printf("WirelessWPACipher: %+v\n", c.device.Data.WirelessWPACipher)
-	fmt.Printf("WirelessWPAMode: %+v\n", c.device.Data.WirelessWPAMode)
-	fmt.Printf("WirelessWPAOn: %+v\n", c.device.Data.WirelessWPAOn)
-	fmt.Printf("WirelessWPAPSK: %+v\n", c.device.Data.WirelessWPAPSK)
+	fmt.Printf("Model: %s\n", c.device.HardwareVersion())
+	fmt.Printf("Status: %s\n", c.device.Status.Description())
+	fmt.Printf("Firmware: %s", c.device.FirmwareVersion())
+	if c.device.OutdatedFirmware() {
+		fmt.Print(" - outdated")
+	}
+	fmt.Println()
+	if fw, ok := c.device.Firmware(); ok {
+		fmt.Printf("Firmware notes: %s\n", fw.Notes)
+	}
+	for _, f := range c.device.SupportedFields() {
+		v, err := c.device.Data.Value(f.Name)
+		if err != nil {
+			continue
+		}
+		if f.Secret && v != "" {
+			v = "********"
+		}
+		fmt.Printf("%s: %s\n", f.Name, v)
+	}
 }
 
 func (c *configurator) setValue(s string) {
@@ -108,84 +87,112 @@ func (c *configurator) setValue(s string) {
 		fmt.Println("set requires a field name and a value.")
 		return
 	}
-	switch strings.ToLower(vals[1]) {
-	case "lanipmode":
-		setBool(vals[2], &c.device.Data.LanIPMode)
-
-	case "lannetworkaddress":
-		setIPAddress(vals[2], &c.device.Data.LanNetworkAddress)
-
-	case "lansubnetmask":
-		setIPAddress(vals[2], &c.device.Data.LanSubnetMask)
-
-	case "langateway":
-		setIPAddress(vals[2], &c.device.Data.LanGateway)
-
-	case "hostname":
-		c.device.Data.Hostname = vals[2]
-
-	case "bridging":
-		setBool(vals[2], &c.device.Data.Bridging)
-
-	case "interface":
-		setUint8(vals[2], &c.device.Data.Interface, maxUint8(1))
-
-	case "primarydns":
-		setIPAddress(vals[2], &c.device.Data.PrimaryDNS)
-
-	case "secondarydns":
-		setIPAddress(vals[2], &c.device.Data.SecondaryDNS)
-
-	case "squeezecenteraddress":
-		setIPAddress(vals[2], &c.device.Data.SqueezeCenterAddress)
-
-	case "wirelessmode":
-		setUint8(vals[2], &c.device.Data.WirelessMode, maxUint8(1))
-
-	case "wirelessssid":
-		c.device.Data.WirelessSSID = strings.TrimPrefix(s, vals[0]+" "+vals[1]+" ")
-
-	case "wirelesschannel":
-		setUint8(vals[2], &c.device.Data.WirelessChannel, anyUint8())
-
-	case "wirelessregion":
-		setUint8(vals[2], &c.device.Data.WirelessRegion, inSetUint8([]uint8{4, 6, 7, 13, 14, 16, 21, 23}))
-
-	case "wirelesskeylen":
-		setUint8(vals[2], &c.device.Data.WirelessKeylen, maxUint8(1))
-
-	case "wirelesswepkey0":
-		c.device.Data.WirelessWEPKey0 = []byte(vals[2])
-
-	case "wirelesswepkey1":
-		c.device.Data.WirelessWEPKey1 = []byte(vals[2])
-
-	case "wirelesswepkey2":
-		c.device.Data.WirelessWEPKey2 = []byte(vals[2])
-
-	case "wirelesswepkey3":
-		c.device.Data.WirelessWEPKey3 = []byte(vals[2])
-
-	case "wirelesswepon":
-		setBool(vals[2], &c.device.Data.WirelessWEPOn)
-
-	case "wirelesswpacipher":
-		setUint8(vals[2], &c.device.Data.WirelessWPACipher, maxUint8(3))
+	if f, ok := gosqueeze.LookupField(vals[1]); ok && !c.device.Model().Supports(f) {
+		fmt.Printf("%s does not apply to the %s.\n", f.Name, c.device.Model().Name)
+		return
+	}
+	value := strings.TrimPrefix(s, vals[0]+" "+vals[1]+" ")
+	if err := c.device.Data.Set(vals[1], value); err != nil {
+		fmt.Printf("%s - value not changed\n", err.Error())
+	}
+}
 
-	case "wirelesswpamode":
-		setUint8(vals[2], &c.device.Data.WirelessWPAMode, maxUint8(2))
+func (c *configurator) showDiff(filename string) {
+	if filename == "" {
+		diffs := c.device.Pending()
+		if len(diffs) == 0 {
+			fmt.Println("No unsaved changes.")
+			return
+		}
+		printDiff(diffs)
+		return
+	}
 
-	case "wirelesswpaon":
-		setBool(vals[2], &c.device.Data.WirelessWPAOn)
+	device, ok := c.device.Fetched()
+	if !ok {
+		fmt.Println("Device data has not been read.")
+		return
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading configuration: %s\n", err.Error())
+		return
+	}
+	file := device
+	if err := file.ImportConfig(b, gosqueeze.ConfigFormatFor(filename)); err != nil {
+		fmt.Printf("Error decoding configuration: %s\n", err.Error())
+		return
+	}
+	diffs := device.Diff(&file, c.device.SupportedFields())
+	if len(diffs) == 0 {
+		fmt.Println("No differences.")
+		return
+	}
+	printDiff(diffs)
+}
 
-	case "wirelesswpapsk":
-		c.device.Data.WirelessWPAPSK = strings.TrimPrefix(s, vals[0]+" "+vals[1]+" ")
+func printDiff(diffs []gosqueeze.Difference) {
+	for _, d := range diffs {
+		fmt.Printf("  %s\n", d.String())
+	}
+}
 
-	default:
-		fmt.Println("Invalid data point specified")
+func (c *configurator) saveValues(s string) {
+	var opts []gosqueeze.SaveOption
+	full := false
+	for _, arg := range strings.Split(s, " ")[1:] {
+		switch arg {
+		case "force":
+			opts = append(opts, gosqueeze.SkipValidation())
+		case "full":
+			full = true
+			opts = append(opts, gosqueeze.FullWrite())
+		case "verify":
+			opts = append(opts, gosqueeze.Verify())
+		default:
+			fmt.Printf("Unknown save option %s.\n", arg)
+			return
+		}
+	}
+	if len(c.device.Modified()) == 0 && !full {
+		fmt.Println("No changes to save.")
+		return
+	}
+	err := c.device.SaveData(c.iface, opts...)
+	if verr, ok := err.(gosqueeze.ValidationError); ok {
+		fmt.Println("Not saved, the following values are invalid:")
+		for _, fe := range verr {
+			fmt.Printf("  %s\n", fe.Error())
+		}
+		fmt.Println("Use 'save force' to save anyway.")
+		return
 	}
+	var verr gosqueeze.VerifyError
+	if errors.As(err, &verr) {
+		if errors.Is(err, gosqueeze.ErrPartialSave) {
+			fmt.Println("The device did not save all values.")
+		}
+		fmt.Println("The following values did not read back as saved:")
+		for _, m := range verr {
+			fmt.Printf("  %s\n", m.String())
+		}
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error saving data: %s\n", err.Error())
+		return
+	}
+	fmt.Println("Successfully set data.")
 }
 
-func (c *configurator) saveValues() {
-	c.device.SaveData(c.iface)
+func (c *configurator) setPassword(password string) {
+	creds, err := gosqueeze.NewCredentials(password)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	c.device.Credentials = &creds
+	if err := c.device.GetData(c.iface); err != nil {
+		fmt.Printf("Error retrieving device data: %s\n", err.Error())
+	}
 }
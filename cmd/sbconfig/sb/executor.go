@@ -18,8 +18,14 @@ func (c *configurator) executor(s string) {
 	case "set":
 		c.setValue(s)
 
+	case "diff":
+		c.showDiff(strings.TrimSpace(strings.TrimPrefix(s, "diff")))
+
 	case "save":
-		c.saveValues()
+		c.saveValues(s)
+
+	case "password":
+		c.setPassword(strings.TrimSpace(strings.TrimPrefix(s, "password")))
 
 	case "exit":
 		fmt.Println("Press Ctrl-D to exit.")
@@ -0,0 +1,182 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jcrummy/gosqueeze/internal/packet"
+)
+
+// reflectEncode and reflectDecode reproduce the reflection based codec
+// the generated one replaced, parsing the gosqueeze tags on every call.
+// They are kept only to compare the two.
+
+func reflectTag(f reflect.StructField) (int, int, bool) {
+	tag := strings.Split(f.Tag.Get("gosqueeze"), ",")
+	if len(tag) < 2 {
+		return 0, 0, false
+	}
+	offset, err := strconv.Atoi(tag[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	length, err := strconv.Atoi(tag[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return offset, length, true
+}
+
+func reflectEncode(d DeviceData) []byte {
+	v := reflect.ValueOf(d)
+	st := v.Type()
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(st.NumField()))
+	for i := 0; i < st.NumField(); i++ {
+		offset, length, ok := reflectTag(st.Field(i))
+		if !ok {
+			continue
+		}
+		header := make([]byte, 4)
+		binary.BigEndian.PutUint16(header[0:2], uint16(offset))
+		binary.BigEndian.PutUint16(header[2:4], uint16(length))
+		data = append(data, header...)
+
+		value := make([]byte, length)
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Bool:
+			if f.Bool() {
+				value[0] = 1
+			}
+		case reflect.String:
+			copy(value, f.String())
+		case reflect.Uint8:
+			value[0] = uint8(f.Uint())
+		case reflect.Slice:
+			copy(value, f.Bytes())
+		}
+		data = append(data, value...)
+	}
+	return data
+}
+
+func reflectDecode(d *DeviceData, payload []byte) error {
+	v := reflect.ValueOf(d).Elem()
+	st := v.Type()
+	offsets := make(map[int]int)
+	for i := 0; i < st.NumField(); i++ {
+		if offset, _, ok := reflectTag(st.Field(i)); ok {
+			offsets[offset] = i
+		}
+	}
+	return packet.Packet{Data: payload}.ParseData(func(offset int, data []byte) (bool, error) {
+		i, ok := offsets[offset]
+		if !ok {
+			return false, nil
+		}
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Bool:
+			f.SetBool(data[0] == 1)
+		case reflect.String:
+			f.SetString(string(bytes.TrimRight(data, "\x00")))
+		case reflect.Uint8:
+			f.SetUint(uint64(data[0]))
+		case reflect.Slice:
+			f.SetBytes(append([]byte(nil), data...))
+		}
+		return true, nil
+	})
+}
+
+func generatedEncode(d DeviceData) []byte {
+	return packet.SaveData(wireFields(Fields), d.encodeField)
+}
+
+func generatedDecode(d *DeviceData, payload []byte) error {
+	return packet.Packet{Data: payload}.ParseData(d.decodeField)
+}
+
+var codecData = DeviceData{
+	LanIPMode:            true,
+	LanNetworkAddress:    net.IPv4(192, 168, 1, 20).To4(),
+	LanSubnetMask:        net.IPv4(255, 255, 255, 0).To4(),
+	LanGateway:           net.IPv4(192, 168, 1, 1).To4(),
+	Hostname:             "SqueezeboxRcv",
+	Interface:            LinkWired,
+	PrimaryDNS:           net.IPv4(192, 168, 1, 1).To4(),
+	SecondaryDNS:         net.IPv4(0, 0, 0, 0).To4(),
+	ActiveServerAddress:  net.IPv4(192, 168, 1, 10).To4(),
+	SqueezeCenterAddress: net.IPv4(192, 168, 1, 10).To4(),
+	SqueezeCenterName:    "mediaserver",
+	WirelessSSID:         "homenet",
+	WirelessRegion:       RegionEU,
+	WirelessWEPKey0:      WEPKey{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13},
+	WirelessWEPKey1:      make(WEPKey, 13),
+	WirelessWEPKey2:      make(WEPKey, 13),
+	WirelessWEPKey3:      make(WEPKey, 13),
+	WirelessWPACipher:    AES,
+	WirelessWPAMode:      WPAModeWPA2,
+	WirelessWPAOn:        true,
+	WirelessWPAPSK:       "passphrase",
+}
+
+func TestCodecMatchesReflection(t *testing.T) {
+	payload := generatedEncode(codecData)
+	if want := reflectEncode(codecData); !bytes.Equal(payload, want) {
+		t.Fatalf("generated encoding differs from reflection:\n% x\n% x", payload, want)
+	}
+
+	var generated, reflected DeviceData
+	if err := generatedDecode(&generated, payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := reflectDecode(&reflected, payload); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(generated, reflected) {
+		t.Errorf("generated decoding differs from reflection:\n%+v\n%+v", generated, reflected)
+	}
+	if !reflect.DeepEqual(generated, codecData) {
+		t.Errorf("decoded data differs from encoded:\n%+v\n%+v", generated, codecData)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	b.Run("generated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			generatedEncode(codecData)
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			reflectEncode(codecData)
+		}
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	payload := generatedEncode(codecData)
+	b.Run("generated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var d DeviceData
+			generatedDecode(&d, payload)
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var d DeviceData
+			reflectDecode(&d, payload)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat is the encoding of an exported configuration.
+type ConfigFormat int
+
+// Configuration file formats
+const (
+	ConfigJSON ConfigFormat = iota
+	ConfigYAML
+)
+
+// ConfigFormatFor returns the configuration format implied by the
+// extension of filename. Files not ending in .yaml or .yml are JSON.
+func ConfigFormatFor(filename string) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return ConfigYAML
+	}
+	return ConfigJSON
+}
+
+// ExportConfig encodes d in the given format. Values are keyed by the
+// json and yaml names of DeviceData, enumerations are written by name
+// and WEP keys as hex. Secret values are included.
+func (d *DeviceData) ExportConfig(format ConfigFormat) ([]byte, error) {
+	switch format {
+	case ConfigJSON:
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case ConfigYAML:
+		return yaml.Marshal(d)
+	}
+	return nil, errors.New("Unknown configuration format")
+}
+
+// ImportConfig applies a configuration encoded by ExportConfig to d.
+// Values missing from the configuration are left unchanged and
+// read-only values are ignored. Unknown names are an error. d is not
+// modified if the configuration can not be decoded.
+func (d *DeviceData) ImportConfig(data []byte, format ConfigFormat) error {
+	imported := *d
+	switch format {
+	case ConfigJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&imported); err != nil {
+			return err
+		}
+	case ConfigYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&imported); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	default:
+		return errors.New("Unknown configuration format")
+	}
+
+	for _, f := range Fields {
+		if f.ReadOnly {
+			imported.decodeField(f.Offset, d.encodeField(f.Offset))
+		}
+	}
+	*d = imported
+	return nil
+}
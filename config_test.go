@@ -0,0 +1,117 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+// configCases are configurations that must survive export and import,
+// including values a device may report that have no name.
+var configCases = []struct {
+	name string
+	data DeviceData
+}{
+	{"zero", DeviceData{}},
+	{"static wired", DeviceData{
+		LanNetworkAddress: net.IPv4(192, 168, 1, 20).To4(),
+		LanSubnetMask:     net.IPv4(255, 255, 255, 0).To4(),
+		LanGateway:        net.IPv4(192, 168, 1, 1).To4(),
+		Hostname:          "receiver",
+		Interface:         LinkWired,
+		PrimaryDNS:        net.IPv4(192, 168, 1, 1).To4(),
+	}},
+	{"wpa", DeviceData{
+		LanIPMode:         true,
+		WirelessSSID:      "home",
+		WirelessRegion:    RegionEU,
+		WirelessWPACipher: AES,
+		WirelessWPAMode:   WPAModeWPA2,
+		WirelessWPAOn:     true,
+		WirelessWPAPSK:    "correct horse",
+	}},
+	{"wep", DeviceData{
+		WirelessKeylen:  1,
+		WirelessWEPKey0: WEPKey("0123456789abc"),
+		WirelessWEPKey2: WEPKey{0xde, 0xad, 0xbe, 0xef, 0x01},
+		WirelessWEPOn:   true,
+	}},
+	{"unlisted enums", DeviceData{
+		Interface:         Link(3),
+		WirelessMode:      WirelessMode(7),
+		WirelessRegion:    Region(99),
+		WirelessWPACipher: WPACipher(255),
+		WirelessWPAMode:   WPAMode(5),
+	}},
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	formats := []struct {
+		name   string
+		format ConfigFormat
+	}{
+		{"json", ConfigJSON},
+		{"yaml", ConfigYAML},
+	}
+	for _, tc := range configCases {
+		for _, f := range formats {
+			t.Run(tc.name+"/"+f.name, func(t *testing.T) {
+				b, err := tc.data.ExportConfig(f.format)
+				if err != nil {
+					t.Fatalf("ExportConfig: %v", err)
+				}
+				var got DeviceData
+				if err := got.ImportConfig(b, f.format); err != nil {
+					t.Fatalf("ImportConfig: %v\n%s", err, b)
+				}
+				want, _ := tc.data.MarshalBinary()
+				image, _ := got.MarshalBinary()
+				if !bytes.Equal(image, want) {
+					t.Errorf("round trip changed the configuration\n%s", b)
+				}
+			})
+		}
+	}
+}
+
+func TestImportConfigKeepsReadOnly(t *testing.T) {
+	d := DeviceData{SqueezeCenterName: "server", ActiveServerAddress: net.IPv4(10, 0, 0, 1).To4()}
+	err := d.ImportConfig([]byte(`{"server_name": "other", "active_server_address": "10.0.0.2", "hostname": "boom"}`), ConfigJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SqueezeCenterName != "server" || !d.ActiveServerAddress.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("read only values changed to %q, %s", d.SqueezeCenterName, d.ActiveServerAddress)
+	}
+	if d.Hostname != "boom" {
+		t.Errorf("Hostname = %q, want boom", d.Hostname)
+	}
+}
+
+func TestImportConfigRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format ConfigFormat
+	}{
+		{"unknown name", `{"colour": "red"}`, ConfigJSON},
+		{"bad region", `{"wireless_region": "XX"}`, ConfigJSON},
+		{"region out of range", `{"wireless_region": "256"}`, ConfigJSON},
+		{"bad wep key", "wireless_wep_key_0: abc\n", ConfigYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DeviceData{Hostname: "receiver"}
+			if err := d.ImportConfig([]byte(tt.data), tt.format); err == nil {
+				t.Fatal("ImportConfig succeeded")
+			}
+			if d.Hostname != "receiver" {
+				t.Error("configuration changed on error")
+			}
+		})
+	}
+}
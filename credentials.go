@@ -0,0 +1,54 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+
+	"github.com/jcrummy/gosqueeze/internal/constants"
+)
+
+// ErrCredentials is returned when a device rejects the credentials of
+// a get or set data request.
+var ErrCredentials = errors.New("Device rejected the credentials")
+
+// Credentials authenticate get and set data requests. Devices without
+// a password accept the zero value.
+type Credentials [32]byte
+
+// DefaultCredentials returns the credentials used for devices whose
+// Credentials are nil, those accepted by devices without a password.
+func DefaultCredentials() Credentials {
+	return Credentials{}
+}
+
+// NewCredentials returns the credentials for a device password of up
+// to 32 characters.
+func NewCredentials(password string) (Credentials, error) {
+	var c Credentials
+	if len(password) > len(c) {
+		return c, errors.New("Password is limited to 32 characters")
+	}
+	copy(c[:], password)
+	return c, nil
+}
+
+// credentials returns the credentials to send to the device.
+func (s *Sb) credentials() []byte {
+	c := DefaultCredentials()
+	if s.Credentials != nil {
+		c = *s.Credentials
+	}
+	return c[:]
+}
+
+// credentialsError returns ErrCredentials if a reply method reports
+// rejected credentials.
+func credentialsError(method int) error {
+	if method == constants.UCPMethodCredentialsError {
+		return ErrCredentials
+	}
+	return nil
+}
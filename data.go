@@ -0,0 +1,48 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+)
+
+//go:generate go run ./internal/gen/datacodec -type DeviceData -registry Fields -output devicedata_codec.go
+
+// dataImageSize is the length of the raw configuration image that all
+// DeviceData values fit into.
+var dataImageSize = func() int {
+	size := 0
+	for _, f := range Fields {
+		if f.Offset+f.Length > size {
+			size = f.Offset + f.Length
+		}
+	}
+	return size
+}()
+
+// MarshalBinary implements encoding.BinaryMarshaler. The configuration
+// is returned as a raw image with each value stored at its offset, as
+// addressed on the device.
+func (d DeviceData) MarshalBinary() ([]byte, error) {
+	image := make([]byte, dataImageSize)
+	for _, f := range Fields {
+		copy(image[f.Offset:f.Offset+f.Length], d.encodeField(f.Offset))
+	}
+	return image, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It populates
+// the configuration from a raw image as returned by MarshalBinary.
+func (d *DeviceData) UnmarshalBinary(image []byte) error {
+	if len(image) < dataImageSize {
+		return errors.New("Configuration image too short")
+	}
+	for _, f := range Fields {
+		if _, err := d.decodeField(f.Offset, image[f.Offset:f.Offset+f.Length]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,16 +5,20 @@
 package gosqueeze
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 	"net"
+	"net/netip"
 	"time"
 
 	"github.com/jcrummy/gosqueeze/internal/broadcast"
 	"github.com/jcrummy/gosqueeze/internal/constants"
 	"github.com/jcrummy/gosqueeze/internal/packet"
+	"github.com/jcrummy/gosqueeze/internal/udap"
+	"github.com/jcrummy/gosqueeze/internal/util"
 )
 
 // Sb represents a squeezebox receiver device
@@ -26,41 +30,76 @@ type Sb struct {
 	ID          uint
 	Type        string
 	Name        string
-	Status      string
+	Status      Status
 	HardwareRev uint
 	FirmwareRev uint
+	UsesDHCP    bool
+	UUID        string
 	Data        DeviceData
+
+	// Credentials authenticate get and set data requests. If nil,
+	// DefaultCredentials() are used.
+	Credentials *Credentials
+
+	// Extra holds discovery values whose UCP code is not recognized,
+	// keyed by code.
+	Extra map[byte][]byte
+
+	// Unknown holds raw values returned by the device at offsets not
+	// covered by DeviceData, keyed by offset.
+	Unknown map[int][]byte
+
+	// fetched is the raw image of Data as last read from or saved to
+	// the device, used to find which values have been modified.
+	fetched []byte
 }
 
 // DeviceData is the configuration data of the device
-// Tagged as offset,data length (in bytes)
+// Tagged as offset,data length (in bytes), optionally followed by
+// readonly for values set by the device and secret for values that
+// should not be displayed. Uint8 values may list their allowed values.
+// The Fields registry is generated from these tags and comments. The
+// json and yaml tags give the stable names used in configuration files.
 type DeviceData struct {
-	LanIPMode            bool   `gosqueeze:"4,1"`    // false = static IP, true = DHCP
-	LanNetworkAddress    net.IP `gosqueeze:"5,4"`    // static network address
-	LanSubnetMask        net.IP `gosqueeze:"9,4"`    // static subnet mask
-	LanGateway           net.IP `gosqueeze:"13,4"`   // static gateway address
-	Hostname             string `gosqueeze:"17,33"`  // device hostname
-	Bridging             bool   `gosqueeze:"50,1"`   // true = use device as wireless bridge
-	Interface            uint8  `gosqueeze:"52,1"`   // 0 = use Wireless, 1 = use Wired
-	PrimaryDNS           net.IP `gosqueeze:"59,4"`   // static primary DNS address
-	SecondaryDNS         net.IP `gosqueeze:"67,4"`   // static secondary DNS address
-	ActiveServerAddress  net.IP `gosqueeze:"71,4"`   // IP address of currently active server (read only)
-	SqueezeCenterAddress net.IP `gosqueeze:"79,4"`   // IP address of local Squeezecenter server
-	SqueezeCenterName    string `gosqueeze:"83,33"`  // Name of local Squeezecenter server (read only)
-	WirelessMode         uint8  `gosqueeze:"173,1"`  // 0 = infrastructure, 1 = Ad Hoc
-	WirelessSSID         string `gosqueeze:"183,33"` // SSID of WiFi access point to connect to
-	WirelessChannel      uint8  `gosqueeze:"216,1"`  // WiFi Channel, can normally leave at 0
-	WirelessRegion       uint8  `gosqueeze:"218,1"`  // 4 = US, 6 = CA, 7 = AU, 13 = FR, 14 = EU, 16 = JP, 21 = TW, 23 = CH
-	WirelessKeylen       uint8  `gosqueeze:"220,1"`  // Length of wireless key (0 = 64-bit, 1 = 128-bit)
-	WirelessWEPKey0      []byte `gosqueeze:"222,13"` // WEP key 0 - in Hex
-	WirelessWEPKey1      []byte `gosqueeze:"235,13"` // WEP key 1 - in Hex
-	WirelessWEPKey2      []byte `gosqueeze:"248,13"` // WEP key 2 - in Hex
-	WirelessWEPKey3      []byte `gosqueeze:"261,13"` // WEP key 3 - in Hex
-	WirelessWEPOn        bool   `gosqueeze:"274,1"`  // 0 = Wep Off, 1 = Wep On
-	WirelessWPACipher    uint8  `gosqueeze:"275,1"`  // 1 = TKIIP, 2 = AES, 3 = TKIP & AES
-	WirelessWPAMode      uint8  `gosqueeze:"276,1"`  // 1 = WPA, 2 = WPA2
-	WirelessWPAOn        bool   `gosqueeze:"277,1"`  // 0 = WPA Off, 1 = WPA On
-	WirelessWPAPSK       string `gosqueeze:"278,64"` // WPA Public Shared Key
+	LanIPMode            bool         `gosqueeze:"4,1" json:"lan_dhcp" yaml:"lan_dhcp"`                                               // false = static IP, true = DHCP
+	LanNetworkAddress    net.IP       `gosqueeze:"5,4" json:"lan_address" yaml:"lan_address"`                                         // static network address
+	LanSubnetMask        net.IP       `gosqueeze:"9,4" json:"lan_subnet_mask" yaml:"lan_subnet_mask"`                                 // static subnet mask
+	LanGateway           net.IP       `gosqueeze:"13,4" json:"lan_gateway" yaml:"lan_gateway"`                                        // static gateway address
+	Hostname             string       `gosqueeze:"17,33" json:"hostname" yaml:"hostname"`                                             // device hostname
+	Bridging             bool         `gosqueeze:"50,1" json:"bridging" yaml:"bridging"`                                              // true = use device as wireless bridge
+	Interface            Link         `gosqueeze:"52,1" values:"0,1" json:"interface" yaml:"interface"`                               // 0 = use Wireless, 1 = use Wired
+	PrimaryDNS           net.IP       `gosqueeze:"59,4" json:"primary_dns" yaml:"primary_dns"`                                        // static primary DNS address
+	SecondaryDNS         net.IP       `gosqueeze:"67,4" json:"secondary_dns" yaml:"secondary_dns"`                                    // static secondary DNS address
+	ActiveServerAddress  net.IP       `gosqueeze:"71,4,readonly" json:"active_server_address" yaml:"active_server_address"`           // IP address of currently active server
+	SqueezeCenterAddress net.IP       `gosqueeze:"79,4" json:"server_address" yaml:"server_address"`                                  // IP address of local Squeezecenter server
+	SqueezeCenterName    string       `gosqueeze:"83,33,readonly" json:"server_name" yaml:"server_name"`                              // Name of local Squeezecenter server
+	WirelessMode         WirelessMode `gosqueeze:"173,1" values:"0,1" json:"wireless_mode" yaml:"wireless_mode"`                      // 0 = infrastructure, 1 = Ad Hoc
+	WirelessSSID         string       `gosqueeze:"183,33" json:"wireless_ssid" yaml:"wireless_ssid"`                                  // SSID of WiFi access point to connect to
+	WirelessChannel      uint8        `gosqueeze:"216,1" json:"wireless_channel" yaml:"wireless_channel"`                             // WiFi Channel, can normally leave at 0
+	WirelessRegion       Region       `gosqueeze:"218,1" values:"4,6,7,13,14,16,21,23" json:"wireless_region" yaml:"wireless_region"` // 4 = US, 6 = CA, 7 = AU, 13 = FR, 14 = EU, 16 = JP, 21 = TW, 23 = CH
+	WirelessKeylen       uint8        `gosqueeze:"220,1" values:"0,1" json:"wireless_wep_key_length" yaml:"wireless_wep_key_length"`  // Length of wireless key (0 = 64-bit, 1 = 128-bit)
+	WirelessWEPKey0      WEPKey       `gosqueeze:"222,13,secret" json:"wireless_wep_key_0" yaml:"wireless_wep_key_0"`                 // WEP key 0
+	WirelessWEPKey1      WEPKey       `gosqueeze:"235,13,secret" json:"wireless_wep_key_1" yaml:"wireless_wep_key_1"`                 // WEP key 1
+	WirelessWEPKey2      WEPKey       `gosqueeze:"248,13,secret" json:"wireless_wep_key_2" yaml:"wireless_wep_key_2"`                 // WEP key 2
+	WirelessWEPKey3      WEPKey       `gosqueeze:"261,13,secret" json:"wireless_wep_key_3" yaml:"wireless_wep_key_3"`                 // WEP key 3
+	WirelessWEPOn        bool         `gosqueeze:"274,1" json:"wireless_wep" yaml:"wireless_wep"`                                     // 0 = Wep Off, 1 = Wep On
+	WirelessWPACipher    WPACipher    `gosqueeze:"275,1" values:"0,1,2,3" json:"wireless_wpa_cipher" yaml:"wireless_wpa_cipher"`      // 1 = TKIP, 2 = AES, 3 = TKIP & AES
+	WirelessWPAMode      WPAMode      `gosqueeze:"276,1" values:"0,1,2" json:"wireless_wpa_mode" yaml:"wireless_wpa_mode"`            // 1 = WPA, 2 = WPA2
+	WirelessWPAOn        bool         `gosqueeze:"277,1" json:"wireless_wpa" yaml:"wireless_wpa"`                                     // 0 = WPA Off, 1 = WPA On
+	WirelessWPAPSK       string       `gosqueeze:"278,64,secret" json:"wireless_wpa_psk" yaml:"wireless_wpa_psk"`                     // WPA Public Shared Key
+}
+
+// ErrTruncatedReply is returned when a reply from a device ends before
+// all of the values it declares.
+var ErrTruncatedReply = errors.New("Reply from device is truncated")
+
+// replyError wraps errors parsing a device reply so callers can match
+// them against the errors of this package.
+func replyError(err error) error {
+	if errors.Is(err, packet.ErrTruncated) {
+		return fmt.Errorf("%w: %w", ErrTruncatedReply, err)
+	}
+	return err
 }
 
 // GetIP retrieves IP address information from the SqueezeBox device
@@ -69,19 +108,15 @@ func (s *Sb) GetIP(iface *net.Interface) error {
 		return errors.New("Hardware address required")
 	}
 
-	p := packet.Packet{
-		DstBroadcast: false,
-		DstAddrType:  constants.AddrTypeEth,
-		DstMac:       s.MacAddr,
-		SrcBroadcast: false,
-		SrcAddrType:  constants.AddrTypeUDP,
-		SrcIP:        constants.IPZero,
-		SrcPort:      0,
-		UcpMethod:    constants.UCPMethodGetIP,
+	packetBytes, err := udap.NewRequest(constants.UCPMethodGetIP).
+		To(s.MacAddr).
+		Assemble()
+	if err != nil {
+		return err
 	}
-	packetBytes := p.Assemble()
 
-	err := broadcast.BroadcastSingle(iface, 17784, packetBytes, 500*time.Millisecond, func(n int, addr *net.UDPAddr, buf []byte) {
+	var parseErr error
+	err = broadcast.BroadcastSingle(iface, 17784, packetBytes, 500*time.Millisecond, func(n int, addr *net.UDPAddr, buf []byte) {
 		p, err := packet.Parse(buf[:n])
 		if err != nil {
 			return
@@ -89,7 +124,7 @@ func (s *Sb) GetIP(iface *net.Interface) error {
 		if p.UcpMethod == constants.UCPMethodGetIP {
 			data, err := p.ParseFields()
 			if err != nil {
-				log.Println(err)
+				parseErr = replyError(err)
 				return
 			}
 			s.populateFields(data)
@@ -98,116 +133,222 @@ func (s *Sb) GetIP(iface *net.Interface) error {
 	if err != nil {
 		return err
 	}
+	if parseErr != nil {
+		return parseErr
+	}
 	if s.IPAddr == nil {
 		return errors.New("Error retrieving IP address")
 	}
 	return nil
 }
 
-// GetData retrieves all data points from the SqueezeBox device
+// GetData retrieves the data points supported by the device's model from
+// the SqueezeBox device. Values returned at offsets not covered by
+// DeviceData are kept in Unknown.
 func (s *Sb) GetData(iface *net.Interface) error {
 	if s.MacAddr == nil {
 		return errors.New("Hardware address required")
 	}
 
-	p := packet.Packet{
-		DstBroadcast: false,
-		DstAddrType:  constants.AddrTypeEth,
-		DstMac:       s.MacAddr,
-		SrcBroadcast: false,
-		SrcAddrType:  constants.AddrTypeUDP,
-		SrcIP:        constants.IPZero,
-		SrcPort:      0,
-		UcpMethod:    constants.UCPMethodGetData,
-	}
-	p.SetDataRetrieve(s.Data)
-	packetBytes := p.Assemble()
-
-	err := broadcast.BroadcastSingle(iface, constants.UdapPort, packetBytes, 500*time.Millisecond, func(n int, addr *net.UDPAddr, buf []byte) {
-		p, err := packet.Parse(buf[:n])
-		if err != nil {
-			return
-		}
-		if p.UcpMethod == constants.UCPMethodGetData {
-			err = p.ParseData(&s.Data)
-			if err != nil {
-				log.Println("Error getting data from device: " + err.Error())
-			}
+	// Decode into a copy so a truncated reply leaves s.Data untouched
+	data := s.Data
+	unknown := make(map[int][]byte)
+	err := s.getData(iface, wireFields(s.SupportedFields()), func(offset int, raw []byte) (bool, error) {
+		known, err := data.decodeField(offset, raw)
+		if !known && !knownRange(offset, len(raw)) {
+			unknown[offset] = util.UnpackBytes(raw)
 		}
+		return true, err
 	})
 	if err != nil {
 		return err
 	}
+	s.Data = data
+	s.fetched, _ = data.MarshalBinary()
+	s.keepUnknown(unknown)
 	return nil
 }
 
-// SaveData saves all current values to the SqueezeBox device permantently
-func (s *Sb) SaveData(iface *net.Interface) error {
-	if s.MacAddr == nil {
-		return errors.New("Hardware address required")
+// ErrPartialSave is returned when the device saves only some of the
+// values sent to it.
+var ErrPartialSave = errors.New("Device did not save all values")
+
+// SaveOption changes how SaveData writes to the device.
+type SaveOption func(*saveOptions)
+
+type saveOptions struct {
+	skipValidation bool
+	fullWrite      bool
+	verify         bool
+}
+
+// SkipValidation saves the data even if it fails Validate.
+func SkipValidation() SaveOption {
+	return func(o *saveOptions) {
+		o.skipValidation = true
 	}
+}
 
-	p := packet.Packet{
-		DstBroadcast: false,
-		DstAddrType:  constants.AddrTypeEth,
-		DstMac:       s.MacAddr,
-		SrcBroadcast: false,
-		SrcAddrType:  constants.AddrTypeUDP,
-		SrcIP:        constants.IPZero,
-		SrcPort:      0,
-		UcpMethod:    constants.UCPMethodSetData,
+// FullWrite saves every writable value, not only those modified since
+// the last GetData.
+func FullWrite() SaveOption {
+	return func(o *saveOptions) {
+		o.fullWrite = true
 	}
-	numDataFields := p.SetDataForSave(s.Data)
-	packetBytes := p.Assemble()
+}
 
-	err := broadcast.BroadcastSingle(iface, constants.UdapPort, packetBytes, 500*time.Millisecond, func(n int, addr *net.UDPAddr, buf []byte) {
-		p, err := packet.Parse(buf[:n])
-		if err != nil {
-			return
+// Verify reads the data back from the device after saving and compares
+// each saved value. Values that differ are returned in a VerifyError.
+func Verify() SaveOption {
+	return func(o *saveOptions) {
+		o.verify = true
+	}
+}
+
+// Modified returns the writable fields of Data that differ from the
+// values last read from or saved to the device. If the data has not
+// been read, every writable field is returned. Fields not supported by
+// the device's model are never returned.
+func (s *Sb) Modified() []Field {
+	var fields []Field
+	for _, f := range s.writableFields() {
+		if s.fetched != nil && bytes.Equal(s.Data.encodeField(f.Offset), s.fetched[f.Offset:f.Offset+f.Length]) {
+			continue
 		}
-		if p.UcpMethod == constants.UCPMethodSetData {
-			numberChanged := int(binary.BigEndian.Uint16(p.Data))
-			if numberChanged == numDataFields {
-				fmt.Println("Successfully set data.")
-			} else {
-				fmt.Println("Error setting data. Not all fields were saved")
-			}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// writableFields returns every field supported by the device's model
+// that is not read only.
+func (s *Sb) writableFields() []Field {
+	var fields []Field
+	for _, f := range s.SupportedFields() {
+		if !f.ReadOnly {
+			fields = append(fields, f)
 		}
-	})
+	}
+	return fields
+}
+
+// SaveData saves modified values to the SqueezeBox device permantently.
+// Only values changed since the last GetData are written, unless the
+// FullWrite option is given; read only values and values not supported
+// by the device's model are never written.
+// Data failing s.Validate is not saved, and the ValidationError is returned,
+// unless the SkipValidation option is given. If the device reports that
+// fewer values were saved than sent, an error matching ErrPartialSave
+// is returned, joined with the VerifyError if the Verify option is given.
+func (s *Sb) SaveData(iface *net.Interface, opts ...SaveOption) error {
+	if s.MacAddr == nil {
+		return errors.New("Hardware address required")
+	}
+
+	var o saveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.skipValidation {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
+	fields := s.Modified()
+	if o.fullWrite {
+		fields = s.writableFields()
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	numberChanged, err := s.setData(iface, wireFields(fields), s.Data.encodeField)
 	if err != nil {
 		return err
 	}
+	if numberChanged != len(fields) {
+		err := fmt.Errorf("%w: %d of %d values saved", ErrPartialSave, numberChanged, len(fields))
+		if o.verify {
+			return errors.Join(err, s.verify(iface, fields))
+		}
+		return err
+	}
+	s.fetched, _ = s.Data.MarshalBinary()
+	if o.verify {
+		return s.verify(iface, fields)
+	}
 	return nil
 }
 
+// Addr returns the IP address of the device. It is invalid if the
+// address has not been retrieved.
+func (s *Sb) Addr() netip.Addr {
+	a, _ := addrFromIP(s.IPAddr)
+	return a
+}
+
+// Prefix returns the IP address and subnet of the device. It is
+// invalid if they have not been retrieved.
+func (s *Sb) Prefix() netip.Prefix {
+	a, ok := addrFromIP(s.IPAddr)
+	if !ok {
+		return netip.Prefix{}
+	}
+	ones, bits := s.SubnetMask.Size()
+	if bits != 32 {
+		return netip.Prefix{}
+	}
+	return netip.PrefixFrom(a, ones)
+}
+
+// Gateway returns the gateway address of the device. It is invalid if
+// the address has not been retrieved.
+func (s *Sb) Gateway() netip.Addr {
+	a, _ := addrFromIP(s.GatewayAddr)
+	return a
+}
+
 // populateFields sets the Sb root field values based on the
-// provided map.
+// provided map. Values with unrecognized codes are kept in Extra.
 func (s *Sb) populateFields(f packet.Fields) {
 	for i, v := range f {
 		switch i {
-		//case UCPCodeZero:
-		//case UCPCodeOne:
 		case constants.UCPCodeDeviceName:
 			s.Name = string(v)
 		case constants.UCPCodeDeviceType:
 			s.Type = string(v)
-		// case UCPodeUseDHCP    :
+		case constants.UCPCodeUseDHCP:
+			if len(v) >= 1 {
+				s.UsesDHCP = v[0] == 0x01
+			}
 		case constants.UCPCodeIPAddr:
-			s.IPAddr = v
+			s.IPAddr = util.UnpackBytes(v)
 		case constants.UCPCodeSubnetMask:
-			s.SubnetMask = v
+			s.SubnetMask = util.UnpackBytes(v)
 		case constants.UCPCodeGatewayAddr:
-			s.GatewayAddr = v
-		// case UCPCodeEight       :
+			s.GatewayAddr = util.UnpackBytes(v)
 		case constants.UCPCodeFirmwareRev:
-			s.FirmwareRev = uint(binary.BigEndian.Uint16(v))
+			if len(v) >= 2 {
+				s.FirmwareRev = uint(binary.BigEndian.Uint16(v))
+			}
 		case constants.UCPCodeHardwareRev:
-			s.HardwareRev = uint(binary.BigEndian.Uint32(v))
+			if len(v) >= 4 {
+				s.HardwareRev = uint(binary.BigEndian.Uint32(v))
+			}
 		case constants.UCPCodeDeviceID:
-			s.ID = uint(binary.BigEndian.Uint16(v))
+			if len(v) >= 2 {
+				s.ID = uint(binary.BigEndian.Uint16(v))
+			}
 		case constants.UCPCodeDeviceStatus:
-			s.Status = string(v)
-			// case UCPCodeUUID :
+			s.Status = Status(util.UnpackString(v))
+		case constants.UCPCodeUUID:
+			s.UUID = hex.EncodeToString(v)
+		default:
+			if s.Extra == nil {
+				s.Extra = make(map[byte][]byte)
+			}
+			s.Extra[i] = util.UnpackBytes(v)
 		}
 	}
 }
@@ -0,0 +1,68 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import "bytes"
+
+// Difference is a configuration value that differs between two
+// DeviceData. From and To of secret values are masked.
+type Difference struct {
+	Field Field
+	From  string
+	To    string
+}
+
+func (d Difference) String() string {
+	if d.Field.Secret {
+		return d.Field.Name + ": value differs"
+	}
+	return d.Field.Name + ": " + d.From + " -> " + d.To
+}
+
+// Diff returns the values of the given fields that differ between d
+// and other, in the order of fields. Pass Fields to compare every value.
+func (d *DeviceData) Diff(other *DeviceData, fields []Field) []Difference {
+	var diffs []Difference
+	for _, f := range fields {
+		if bytes.Equal(d.encodeField(f.Offset), other.encodeField(f.Offset)) {
+			continue
+		}
+		from, _ := d.Value(f.Name)
+		to, _ := other.Value(f.Name)
+		if f.Secret {
+			from, to = maskSecret(from), maskSecret(to)
+		}
+		diffs = append(diffs, Difference{Field: f, From: from, To: to})
+	}
+	return diffs
+}
+
+// maskSecret hides a secret value, leaving an empty value visible.
+func maskSecret(v string) string {
+	if v == "" {
+		return v
+	}
+	return "********"
+}
+
+// Fetched returns the values last read from or saved to the device, or
+// false if the data has not been read.
+func (s *Sb) Fetched() (DeviceData, bool) {
+	var d DeviceData
+	if s.fetched == nil {
+		return d, false
+	}
+	if err := d.UnmarshalBinary(s.fetched); err != nil {
+		return d, false
+	}
+	return d, true
+}
+
+// Pending returns the modified values of Data that SaveData would
+// write, compared with the values last read from or saved to the device.
+func (s *Sb) Pending() []Difference {
+	device, _ := s.Fetched()
+	return device.Diff(&s.Data, s.Modified())
+}
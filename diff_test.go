@@ -0,0 +1,73 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	from := DeviceData{Hostname: "kitchen", WirelessRegion: RegionUS, WirelessWPAPSK: "old passphrase"}
+	to := from
+	to.Hostname = "lounge"
+	to.WirelessRegion = RegionEU
+	to.LanGateway = net.IPv4(192, 168, 1, 1).To4()
+	to.WirelessWPAPSK = "new passphrase"
+
+	diffs := from.Diff(&to, Fields)
+	got := make(map[string]Difference)
+	var order []string
+	for _, d := range diffs {
+		got[d.Field.Name] = d
+		order = append(order, d.Field.Name)
+	}
+	want := []string{"LanGateway", "Hostname", "WirelessRegion", "WirelessWPAPSK"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Fatalf("Diff() fields = %v, want %v", order, want)
+	}
+	if d := got["Hostname"]; d.From != "kitchen" || d.To != "lounge" {
+		t.Errorf("Hostname = %+v", d)
+	}
+	if d := got["WirelessRegion"]; d.String() != "WirelessRegion: US -> EU" {
+		t.Errorf("WirelessRegion = %s", d.String())
+	}
+	if d := got["LanGateway"]; d.To != "192.168.1.1" {
+		t.Errorf("LanGateway = %+v", d)
+	}
+	psk := got["WirelessWPAPSK"]
+	if strings.Contains(psk.From+psk.To+psk.String(), "passphrase") {
+		t.Errorf("secret value shown: %+v, %s", psk, psk.String())
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	from := DeviceData{Hostname: "kitchen", WirelessSSID: "home"}
+	to := DeviceData{Hostname: "lounge", WirelessSSID: "work"}
+	hostname, _ := LookupField("Hostname")
+	diffs := from.Diff(&to, []Field{hostname})
+	if len(diffs) != 1 || diffs[0].Field.Name != "Hostname" {
+		t.Errorf("Diff() = %v, want only Hostname", diffs)
+	}
+	if diffs := from.Diff(&to, nil); len(diffs) != 0 {
+		t.Errorf("Diff() with no fields = %v", diffs)
+	}
+	if diffs := from.Diff(&from, Fields); len(diffs) != 0 {
+		t.Errorf("Diff() of equal data = %v", diffs)
+	}
+}
+
+func TestPending(t *testing.T) {
+	s := Sb{ID: 7, Data: DeviceData{Hostname: "kitchen"}}
+	s.fetched, _ = s.Data.MarshalBinary()
+	if p := s.Pending(); len(p) != 0 {
+		t.Errorf("Pending() with nothing modified = %v", p)
+	}
+	s.Data.Hostname = "lounge"
+	if p := s.Pending(); len(p) != 1 || p[0].From != "kitchen" || p[0].To != "lounge" {
+		t.Errorf("Pending() = %v", p)
+	}
+}
@@ -5,33 +5,50 @@
 package gosqueeze
 
 import (
-	"log"
 	"net"
 	"time"
 
 	"github.com/jcrummy/gosqueeze/internal/broadcast"
 	"github.com/jcrummy/gosqueeze/internal/constants"
 	"github.com/jcrummy/gosqueeze/internal/packet"
+	"github.com/jcrummy/gosqueeze/internal/udap"
+	"github.com/jcrummy/gosqueeze/internal/util"
 )
 
-// Discover returns a list of squeezebox devices found on the network
-func Discover(iface *net.Interface) ([]Sb, error) {
+// DiscoverOption changes how Discover sets up the devices it finds.
+type DiscoverOption func(*discoverOptions)
+
+type discoverOptions struct {
+	credentials *Credentials
+}
+
+// WithCredentials sets the Credentials of every device found, for
+// networks where the devices share a password.
+func WithCredentials(c Credentials) DiscoverOption {
+	return func(o *discoverOptions) {
+		o.credentials = &c
+	}
+}
+
+// Discover returns a list of squeezebox devices found on the network.
+// If some replies are truncated, the devices that were found are
+// returned along with an error matching ErrTruncatedReply.
+func Discover(iface *net.Interface, opts ...DiscoverOption) ([]Sb, error) {
+	var o discoverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// Put together packet to send
-	p := packet.Packet{
-		DstBroadcast: true,
-		DstAddrType:  constants.AddrTypeEth,
-		DstMac:       constants.MacZero,
-		SrcBroadcast: false,
-		SrcAddrType:  constants.AddrTypeUDP,
-		SrcIP:        constants.IPZero,
-		SrcPort:      0,
-		UcpMethod:    constants.UCPMethodAdvDiscover,
+	packetBytes, err := udap.NewRequest(constants.UCPMethodAdvDiscover).Assemble()
+	if err != nil {
+		return nil, err
 	}
-	packetBytes := p.Assemble()
 
 	var sb []Sb
+	var parseErr error
 
-	err := broadcast.BroadcastReceive(iface, 17784, packetBytes, 3*time.Second, func(n int, addr *net.UDPAddr, buf []byte) {
+	err = broadcast.BroadcastReceive(iface, 17784, packetBytes, 3*time.Second, func(n int, addr *net.UDPAddr, buf []byte) {
 		p, err := packet.Parse(buf[:n])
 		if err != nil {
 			return
@@ -39,17 +56,21 @@ func Discover(iface *net.Interface) ([]Sb, error) {
 		if p.UcpMethod == constants.UCPMethodAdvDiscover {
 			data, err := p.ParseFields()
 			if err != nil {
-				log.Println(err)
+				parseErr = replyError(err)
 				return
 			}
-			foundSB := Sb{MacAddr: p.SrcMac}
+			foundSB := Sb{MacAddr: util.UnpackBytes(p.SrcMac)}
+			if o.credentials != nil {
+				creds := *o.credentials
+				foundSB.Credentials = &creds
+			}
 			foundSB.populateFields(data)
 			sb = append(sb, foundSB)
 		}
 	})
 	if err != nil {
-		return nil, err
+		return sb, err
 	}
 
-	return sb, nil
+	return sb, parseErr
 }
@@ -0,0 +1,224 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Region is the regulatory region the wireless radio operates in.
+type Region uint8
+
+// Wireless regions
+const (
+	RegionUS Region = 4
+	RegionCA Region = 6
+	RegionAU Region = 7
+	RegionFR Region = 13
+	RegionEU Region = 14
+	RegionJP Region = 16
+	RegionTW Region = 21
+	RegionCH Region = 23
+)
+
+var regionNames = map[uint8]string{
+	uint8(RegionUS): "US",
+	uint8(RegionCA): "CA",
+	uint8(RegionAU): "AU",
+	uint8(RegionFR): "FR",
+	uint8(RegionEU): "EU",
+	uint8(RegionJP): "JP",
+	uint8(RegionTW): "TW",
+	uint8(RegionCH): "CH",
+}
+
+func (r Region) String() string { return enumString("Region", uint8(r), regionNames) }
+
+// Valid reports whether r is a known region.
+func (r Region) Valid() bool { return enumValid(uint8(r), regionNames) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Region) MarshalText() ([]byte, error) { return enumMarshal(uint8(r), regionNames) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the
+// region name or its number.
+func (r *Region) UnmarshalText(text []byte) error {
+	return enumUnmarshal("region", text, regionNames, (*uint8)(r))
+}
+
+// WirelessMode is the kind of wireless network joined.
+type WirelessMode uint8
+
+// Wireless modes
+const (
+	WirelessModeInfrastructure WirelessMode = 0
+	WirelessModeAdHoc          WirelessMode = 1
+)
+
+var wirelessModeNames = map[uint8]string{
+	uint8(WirelessModeInfrastructure): "infrastructure",
+	uint8(WirelessModeAdHoc):          "adhoc",
+}
+
+func (m WirelessMode) String() string {
+	return enumString("WirelessMode", uint8(m), wirelessModeNames)
+}
+
+// Valid reports whether m is a known wireless mode.
+func (m WirelessMode) Valid() bool { return enumValid(uint8(m), wirelessModeNames) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (m WirelessMode) MarshalText() ([]byte, error) {
+	return enumMarshal(uint8(m), wirelessModeNames)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the
+// mode name or its number.
+func (m *WirelessMode) UnmarshalText(text []byte) error {
+	return enumUnmarshal("wireless mode", text, wirelessModeNames, (*uint8)(m))
+}
+
+// Link is the network link the device uses.
+type Link uint8
+
+// Network links
+const (
+	LinkWireless Link = 0
+	LinkWired    Link = 1
+)
+
+var linkNames = map[uint8]string{
+	uint8(LinkWireless): "wireless",
+	uint8(LinkWired):    "wired",
+}
+
+func (l Link) String() string { return enumString("Link", uint8(l), linkNames) }
+
+// Valid reports whether l is a known link.
+func (l Link) Valid() bool { return enumValid(uint8(l), linkNames) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Link) MarshalText() ([]byte, error) { return enumMarshal(uint8(l), linkNames) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the
+// link name or its number.
+func (l *Link) UnmarshalText(text []byte) error {
+	return enumUnmarshal("link", text, linkNames, (*uint8)(l))
+}
+
+// WPACipher is the cipher used for WPA encryption.
+type WPACipher uint8
+
+// WPA ciphers
+const (
+	NoCipher WPACipher = 0
+	TKIP     WPACipher = 1
+	AES      WPACipher = 2
+	TKIPAES  WPACipher = 3
+)
+
+var wpaCipherNames = map[uint8]string{
+	uint8(NoCipher): "none",
+	uint8(TKIP):     "tkip",
+	uint8(AES):      "aes",
+	uint8(TKIPAES):  "tkip+aes",
+}
+
+func (c WPACipher) String() string { return enumString("WPACipher", uint8(c), wpaCipherNames) }
+
+// Valid reports whether c is a known cipher.
+func (c WPACipher) Valid() bool { return enumValid(uint8(c), wpaCipherNames) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (c WPACipher) MarshalText() ([]byte, error) { return enumMarshal(uint8(c), wpaCipherNames) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the
+// cipher name or its number.
+func (c *WPACipher) UnmarshalText(text []byte) error {
+	return enumUnmarshal("WPA cipher", text, wpaCipherNames, (*uint8)(c))
+}
+
+// WPAMode is the version of WPA used.
+type WPAMode uint8
+
+// WPA modes
+const (
+	WPAModeNone WPAMode = 0
+	WPAModeWPA  WPAMode = 1
+	WPAModeWPA2 WPAMode = 2
+)
+
+var wpaModeNames = map[uint8]string{
+	uint8(WPAModeNone): "none",
+	uint8(WPAModeWPA):  "wpa",
+	uint8(WPAModeWPA2): "wpa2",
+}
+
+func (m WPAMode) String() string { return enumString("WPAMode", uint8(m), wpaModeNames) }
+
+// Valid reports whether m is a known WPA mode.
+func (m WPAMode) Valid() bool { return enumValid(uint8(m), wpaModeNames) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (m WPAMode) MarshalText() ([]byte, error) { return enumMarshal(uint8(m), wpaModeNames) }
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the
+// mode name or its number.
+func (m *WPAMode) UnmarshalText(text []byte) error {
+	return enumUnmarshal("WPA mode", text, wpaModeNames, (*uint8)(m))
+}
+
+// enumFields maps configuration values holding an enumerated type to
+// the names of their values.
+var enumFields = map[string]map[uint8]string{
+	"Interface":         linkNames,
+	"WirelessMode":      wirelessModeNames,
+	"WirelessRegion":    regionNames,
+	"WirelessWPACipher": wpaCipherNames,
+	"WirelessWPAMode":   wpaModeNames,
+}
+
+// enumString returns the name of v, or the type and number if v is unknown.
+func enumString(typeName string, v uint8, names map[uint8]string) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return typeName + "(" + strconv.Itoa(int(v)) + ")"
+}
+
+func enumValid(v uint8, names map[uint8]string) bool {
+	_, ok := names[v]
+	return ok
+}
+
+// enumMarshal returns the name of v, or its number if v is unknown.
+func enumMarshal(v uint8, names map[uint8]string) ([]byte, error) {
+	if name, ok := names[v]; ok {
+		return []byte(name), nil
+	}
+	return []byte(strconv.Itoa(int(v))), nil
+}
+
+// enumUnmarshal parses a value name, matched regardless of case, or
+// any number from 0 to 255 into target, so that every value written by
+// enumMarshal reads back. Whether a number is known is left to Valid
+// and Validate. Other text is rejected and target is left unchanged.
+func enumUnmarshal(kind string, text []byte, names map[uint8]string, target *uint8) error {
+	s := string(text)
+	for v, name := range names {
+		if strings.EqualFold(name, s) {
+			*target = v
+			return nil
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return errors.New("Unknown " + kind + " " + s)
+	}
+	*target = uint8(n)
+	return nil
+}
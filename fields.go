@@ -0,0 +1,193 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/jcrummy/gosqueeze/internal/util"
+)
+
+// FieldType identifies how a configuration value is represented.
+type FieldType int
+
+// Configuration value types
+const (
+	FieldBool FieldType = iota
+	FieldString
+	FieldUint8
+	FieldIP
+	FieldBytes
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldBool:
+		return "bool"
+	case FieldString:
+		return "string"
+	case FieldUint8:
+		return "uint8"
+	case FieldIP:
+		return "ip"
+	case FieldBytes:
+		return "bytes"
+	}
+	return "unknown"
+}
+
+// Field describes a configuration value of DeviceData: where it is
+// stored on the device and how it may be set.
+type Field struct {
+	Name        string
+	Offset      int
+	Length      int
+	Type        FieldType
+	ReadOnly    bool    // set by the device, never saved
+	Secret      bool    // should not be displayed
+	Values      []uint8 // allowed values, or nil if any value is allowed
+	Description string
+}
+
+// LookupField returns the registry entry of the named configuration
+// value. Names are matched regardless of case.
+func LookupField(name string) (Field, bool) {
+	for _, f := range Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Allowed reports whether v is an allowed value of a uint8 field.
+func (f Field) Allowed(v uint8) bool {
+	if f.Values == nil {
+		return true
+	}
+	for _, a := range f.Values {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the named configuration value of d formatted as text.
+// Enumerated values are returned by name and WEP keys as hex digits.
+func (d *DeviceData) Value(name string) (string, error) {
+	f, ok := LookupField(name)
+	if !ok {
+		return "", errors.New("Unknown field " + name)
+	}
+	raw := d.encodeField(f.Offset)
+	switch f.Type {
+	case FieldBool:
+		v, _ := util.UnpackBool(raw)
+		return strconv.FormatBool(v), nil
+	case FieldString:
+		return util.UnpackString(raw), nil
+	case FieldUint8:
+		v, _ := util.UnpackUint8(raw)
+		if names, ok := enumFields[f.Name]; ok {
+			text, _ := enumMarshal(v, names)
+			return string(text), nil
+		}
+		return strconv.Itoa(int(v)), nil
+	case FieldIP:
+		return net.IP(raw).String(), nil
+	}
+	return WEPKey(raw).Trim(d.WEPKeySize()).String(), nil
+}
+
+// Set parses text and stores it as the named configuration value of d.
+// Enumerated values may be given by name or number, and WEP keys as hex
+// digits or ASCII characters of the size selected by WirelessKeylen.
+// Read only values and values not allowed by the registry are rejected.
+func (d *DeviceData) Set(name string, text string) error {
+	f, ok := LookupField(name)
+	if !ok {
+		return errors.New("Unknown field " + name)
+	}
+	if f.ReadOnly {
+		return errors.New(f.Name + " is read only")
+	}
+
+	var raw []byte
+	switch f.Type {
+	case FieldBool:
+		v, err := parseBool(text)
+		if err != nil {
+			return err
+		}
+		raw = util.PackBool(v, f.Length)
+
+	case FieldString:
+		if len(text) > f.Length {
+			return fmt.Errorf("%s is limited to %d characters", f.Name, f.Length)
+		}
+		raw = util.PackString(text, f.Length)
+
+	case FieldUint8:
+		var v uint8
+		if names, ok := enumFields[f.Name]; ok {
+			if err := enumUnmarshal(f.Name, []byte(text), names, &v); err != nil {
+				return err
+			}
+		} else {
+			n, err := strconv.ParseUint(text, 10, 8)
+			if err != nil {
+				return errors.New("Not a number from 0 to 255")
+			}
+			v = uint8(n)
+		}
+		if !f.Allowed(v) {
+			return fmt.Errorf("%d is not an allowed value of %s", v, f.Name)
+		}
+		raw = util.PackUint8(v, f.Length)
+
+	case FieldIP:
+		ip := net.ParseIP(text).To4()
+		if ip == nil {
+			return errors.New("Invalid IP address - write in form of x.x.x.x")
+		}
+		raw = util.PackBytes(ip, f.Length)
+
+	case FieldBytes:
+		key, err := ParseWEPKey(text, d.WEPKeySize())
+		if err != nil {
+			return err
+		}
+		raw = key
+	}
+
+	_, err := d.decodeField(f.Offset, raw)
+	return err
+}
+
+// parseBool parses the forms of true and false accepted by Set.
+func parseBool(text string) (bool, error) {
+	switch strings.ToLower(text) {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, errors.New("Invalid true/false value")
+}
+
+// wireFields returns the offset and length of each field, as listed in
+// get and set data requests.
+func wireFields(fields []Field) []util.Field {
+	ret := make([]util.Field, len(fields))
+	for i, f := range fields {
+		ret[i] = util.Field{Name: f.Name, Offset: f.Offset, Length: f.Length}
+	}
+	return ret
+}
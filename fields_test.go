@@ -0,0 +1,63 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		text  string
+		ok    bool
+		check func(d *DeviceData) bool
+	}{
+		{"bool true", "LanIPMode", "yes", true, func(d *DeviceData) bool { return d.LanIPMode }},
+		{"bool false", "WirelessWPAOn", "0", true, func(d *DeviceData) bool { return !d.WirelessWPAOn }},
+		{"bool invalid", "LanIPMode", "maybe", false, nil},
+		{"string", "Hostname", "kitchen", true, func(d *DeviceData) bool { return d.Hostname == "kitchen" }},
+		{"string too long", "Hostname", strings.Repeat("a", 34), false, nil},
+		{"uint8", "WirelessChannel", "11", true, func(d *DeviceData) bool { return d.WirelessChannel == 11 }},
+		{"uint8 not a number", "WirelessChannel", "eleven", false, nil},
+		{"uint8 out of range", "WirelessChannel", "256", false, nil},
+		{"allowed value", "WirelessRegion", "14", true, func(d *DeviceData) bool { return d.WirelessRegion == 14 }},
+		{"value not allowed", "WirelessRegion", "5", false, nil},
+		{"ip", "LanGateway", "192.168.1.1", true, func(d *DeviceData) bool { return d.LanGateway.Equal(net.IPv4(192, 168, 1, 1)) }},
+		{"ip invalid", "LanGateway", "192.168.1", false, nil},
+		{"ipv6", "LanGateway", "fd00::1", false, nil},
+		{"name regardless of case", "hostname", "boom", true, func(d *DeviceData) bool { return d.Hostname == "boom" }},
+		{"read only", "SqueezeCenterName", "server", false, nil},
+		{"unknown field", "Colour", "red", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d DeviceData
+			err := d.Set(tt.field, tt.text)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Set(%s, %q) error = %v, want ok %v", tt.field, tt.text, err, tt.ok)
+			}
+			if tt.check != nil && !tt.check(&d) {
+				t.Errorf("Set(%s, %q) stored %+v", tt.field, tt.text, d)
+			}
+		})
+	}
+}
+
+func TestFieldsCoverDeviceData(t *testing.T) {
+	for i, f := range Fields {
+		if f.Length <= 0 {
+			t.Errorf("%s has length %d", f.Name, f.Length)
+		}
+		for _, g := range Fields[i+1:] {
+			if f.Offset < g.Offset+g.Length && g.Offset < f.Offset+f.Length {
+				t.Errorf("%s overlaps %s", f.Name, g.Name)
+			}
+		}
+	}
+}
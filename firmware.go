@@ -0,0 +1,96 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Firmware describes a known firmware release of a model.
+type Firmware struct {
+	ModelID  uint
+	Revision uint
+	Notes    string
+}
+
+// KnownFirmware lists firmware releases with notes on their behaviour,
+// newest last for each model. Add entries here as releases and their
+// quirks are confirmed.
+var KnownFirmware = []Firmware{
+	{ModelID: 4, Revision: 137, Notes: "Final squeezebox2 image, shared by the Squeezebox 2 and 3; setup is normally done from the player's own display"},
+	{ModelID: 5, Revision: 87, Notes: "Final transporter image; setup is normally done from the player's own display"},
+	{ModelID: 7, Revision: 77, Notes: "Final receiver image; the Receiver has no display, so setup mode is its only local configuration"},
+	{ModelID: 10, Revision: 57, Notes: "Final boom image"},
+}
+
+// MinimumFirmware holds the lowest acceptable firmware revision of each
+// model, keyed by device ID. Revisions are numbered separately for each
+// model. Devices of models not listed are never reported as outdated.
+var MinimumFirmware = map[uint]uint{}
+
+// LatestFirmware returns the newest known firmware release of a model.
+func LatestFirmware(modelID uint) (Firmware, bool) {
+	var latest Firmware
+	found := false
+	for _, fw := range KnownFirmware {
+		if fw.ModelID == modelID && (!found || fw.Revision > latest.Revision) {
+			latest = fw
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// FirmwareVersion returns the firmware of the device as a version
+// string naming the firmware image of its model and the revision, such
+// as "receiver r77", noting whether a newer release is known.
+func (s *Sb) FirmwareVersion() string {
+	v := "r" + strconv.Itoa(int(s.FirmwareRev))
+	if image := s.Model().Firmware; image != "" {
+		v = image + " " + v
+	}
+	if latest, ok := LatestFirmware(s.ID); ok {
+		switch {
+		case s.FirmwareRev == latest.Revision:
+			v += " (latest)"
+		case s.FirmwareRev < latest.Revision:
+			v += fmt.Sprintf(" (r%d available)", latest.Revision)
+		}
+	}
+	return v
+}
+
+// HardwareVersion returns the model name and hardware revision of the
+// device as a string. Models not found in Models are described by
+// their device ID and type.
+func (s *Sb) HardwareVersion() string {
+	name := s.Model().Name
+	if name == UnknownModel.Name {
+		name = fmt.Sprintf("Unknown model %d", s.ID)
+		if s.Type != "" {
+			name += " (" + s.Type + ")"
+		}
+	}
+	return fmt.Sprintf("%s, hardware revision %d", name, s.HardwareRev)
+}
+
+// Firmware returns the known firmware entry for the firmware the
+// device is running, if there is one.
+func (s *Sb) Firmware() (Firmware, bool) {
+	for _, fw := range KnownFirmware {
+		if fw.ModelID == s.ID && fw.Revision == s.FirmwareRev {
+			return fw, true
+		}
+	}
+	return Firmware{}, false
+}
+
+// OutdatedFirmware reports whether the device runs firmware older than
+// the minimum set for its model in MinimumFirmware.
+func (s *Sb) OutdatedFirmware() bool {
+	min, ok := MinimumFirmware[s.ID]
+	return ok && s.FirmwareRev < min
+}
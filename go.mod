@@ -2,7 +2,10 @@ module github.com/jcrummy/gosqueeze
 
 go 1.22
 
-require github.com/c-bata/go-prompt v0.2.6
+require (
+	github.com/c-bata/go-prompt v0.2.6
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
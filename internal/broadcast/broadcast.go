@@ -13,6 +13,11 @@ import (
 	"time"
 )
 
+// MaxDatagramSize is the largest UDP payload that can be carried over
+// IPv4. Replies are read into a buffer of this size, so they are never
+// cut short.
+const MaxDatagramSize = 65507
+
 // BroadcastReceive sends a provided message out as a UDP broadcast on the provided port and
 // waits for a reply on the same port. Handler function handler is called to process each reply.
 // This means that the handler function will be called as many times are there
@@ -59,14 +64,13 @@ func BroadcastReceive(iface *net.Interface, sendPort int, sendMsg []byte, timeou
 
 	rconn.SetDeadline(time.Now().Add(timeout))
 	for {
-		buf := make([]byte, 1024)
-		n, addr, err := rconn.ReadFromUDP(buf)
+		n, addr, buf, err := readReply(rconn)
 		if err != nil {
-			if err.(net.Error).Timeout() {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				break
-			} else {
-				log.Println(err)
 			}
+			log.Println(err)
+			continue
 		}
 		handler(n, addr, buf)
 	}
@@ -118,9 +122,8 @@ func BroadcastSingle(iface *net.Interface, sendPort int, sendMsg []byte, timeout
 	}
 	defer rconn.Close()
 
-	buf := make([]byte, 1024)
 	rconn.SetDeadline(time.Now().Add(timeout))
-	n, addr, err := rconn.ReadFromUDP(buf)
+	n, addr, buf, err := readReply(rconn)
 	if err != nil {
 		return err
 	}
@@ -129,6 +132,17 @@ func BroadcastSingle(iface *net.Interface, sendPort int, sendMsg []byte, timeout
 	return nil
 }
 
+// readReply reads a single datagram from conn into a buffer large
+// enough for any datagram.
+func readReply(conn *net.UDPConn) (int, *net.UDPAddr, []byte, error) {
+	buf := make([]byte, MaxDatagramSize)
+	n, addr, err := conn.ReadFromUDP(buf)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return n, addr, buf, nil
+}
+
 // getIfaceAddr returns the IPv4 address associated with an interface.
 func getIfaceAddr(iface *net.Interface) (string, error) {
 	laddrs, err := iface.Addrs()
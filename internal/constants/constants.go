@@ -41,22 +41,23 @@ const (
 	UCPMethodGetUUID
 )
 
-// UCP Codes
+// UCP Codes identify the values in discovery and get IP replies. Codes
+// whose meaning is unknown are kept by number on the device.
 const (
-	UCPCodeZero = iota
-	UCPCodeOne
-	UCPCodeDeviceName
-	UCPCodeDeviceType
-	UCPodeUseDHCP
-	UCPCodeIPAddr
-	UCPCodeSubnetMask
-	UCPCodeGatewayAddr
-	UCPCodeEight
-	UCPCodeFirmwareRev
-	UCPCodeHardwareRev
-	UCPCodeDeviceID
-	UCPCodeDeviceStatus
-	UCPCodeUUID
+	UCPCodeZero         = iota // unknown
+	UCPCodeOne                 // unknown
+	UCPCodeDeviceName          // device name, as text
+	UCPCodeDeviceType          // device type, as text
+	UCPCodeUseDHCP             // 1 byte, 1 when the address was obtained by DHCP
+	UCPCodeIPAddr              // 4 byte IPv4 address
+	UCPCodeSubnetMask          // 4 byte subnet mask
+	UCPCodeGatewayAddr         // 4 byte gateway address
+	UCPCodeEight               // unknown
+	UCPCodeFirmwareRev         // 2 byte firmware revision
+	UCPCodeHardwareRev         // 4 byte hardware revision
+	UCPCodeDeviceID            // 2 byte device ID, identifying the model
+	UCPCodeDeviceStatus        // device status, as text
+	UCPCodeUUID                // device UUID, as raw bytes
 )
 
 const (
@@ -72,6 +73,12 @@ const (
 	DataSqueezeCenterAddress
 )
 
+// Header defaults
+const (
+	UdapTypeUCPValue = 0xC001
+	UcpFlagsRequest  = 0x01
+)
+
 // Misc constants
 var (
 	UapClassUCP        = []byte{0x00, 0x01, 0x00, 0x01}
@@ -0,0 +1,332 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+// Datacodec generates a field registry and a typed encoder and decoder
+// for a struct whose fields are tagged with gosqueeze:"offset,length".
+// It is run with go generate from the directory of the package
+// declaring the struct:
+//
+//	//go:generate go run ./internal/gen/datacodec -type DeviceData -registry Fields -output devicedata_codec.go
+//
+// The generated file declares the registry, a slice of Field values
+// describing each tagged field, and the methods encodeField and
+// decodeField, which convert the field stored at an offset to and from
+// its raw bytes. The package must declare the Field type and a
+// FieldType constant for each supported type.
+//
+// Besides the offset and length, the gosqueeze tag may list the options
+// readonly and secret. A values tag lists the values allowed for a
+// uint8 field, and the line comment of a field is used as its
+// description.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jcrummy/gosqueeze/internal/util"
+)
+
+// field is a tagged struct field along with how it is converted.
+type field struct {
+	util.Field
+	kind        string // one of the supported underlying types
+	typeName    string // name of a locally declared type, if any
+	readOnly    bool
+	secret      bool
+	values      []int
+	description string
+}
+
+// fieldTypes maps supported underlying types to the FieldType
+// constant describing them.
+var fieldTypes = map[string]string{
+	"bool":   "FieldBool",
+	"string": "FieldString",
+	"uint8":  "FieldUint8",
+	"net.IP": "FieldIP",
+	"[]byte": "FieldBytes",
+}
+
+func main() {
+	typeName := flag.String("type", "", "name of the struct type to generate a codec for")
+	registry := flag.String("registry", "", "name of the generated field registry")
+	output := flag.String("output", "", "output file name")
+	flag.Parse()
+	if *typeName == "" || *registry == "" || *output == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	types, pkgName, err := parseTypes(".", *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fields, err := structFields(types, *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(pkgName, *typeName, *registry, fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseTypes returns the type declarations of the package in dir,
+// ignoring test files and the generated output file.
+func parseTypes(dir string, output string) (map[string]ast.Expr, string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(pkgs) != 1 {
+		return nil, "", errors.New("Expected a single package in " + dir)
+	}
+
+	types := make(map[string]ast.Expr)
+	var pkgName string
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					types[ts.Name.Name] = ts.Type
+				}
+			}
+		}
+	}
+	return types, pkgName, nil
+}
+
+// structFields returns the tagged fields of the named struct, ordered
+// as declared.
+func structFields(types map[string]ast.Expr, name string) ([]field, error) {
+	expr, ok := types[name]
+	if !ok {
+		return nil, errors.New("Type " + name + " not found")
+	}
+	st, ok := expr.(*ast.StructType)
+	if !ok {
+		return nil, errors.New("Type " + name + " is not a struct")
+	}
+
+	var fields []field
+	offsets := make(map[int]string)
+	for _, f := range st.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		kind, typeName, err := resolveKind(types, f.Type)
+		if err != nil {
+			return nil, err
+		}
+		options, values, err := tagOptions(reflect.StructTag(tag))
+		if err != nil {
+			return nil, err
+		}
+		if values != nil && kind != "uint8" {
+			return nil, errors.New("Allowed values are only supported for uint8 fields")
+		}
+		var description string
+		if f.Comment != nil {
+			description = strings.TrimSpace(f.Comment.Text())
+		}
+		for _, n := range f.Names {
+			offset, length, err := util.GetTag(reflect.StructField{Name: n.Name, Tag: reflect.StructTag(tag)})
+			if err != nil {
+				continue
+			}
+			if other, ok := offsets[offset]; ok {
+				return nil, fmt.Errorf("Fields %s and %s share offset %d", other, n.Name, offset)
+			}
+			offsets[offset] = n.Name
+			fields = append(fields, field{
+				Field:       util.Field{Name: n.Name, Offset: offset, Length: length},
+				kind:        kind,
+				typeName:    typeName,
+				readOnly:    options["readonly"],
+				secret:      options["secret"],
+				values:      values,
+				description: description,
+			})
+		}
+	}
+	return fields, nil
+}
+
+// tagOptions returns the options following the offset and length in
+// the gosqueeze tag, and the allowed values listed in the values tag.
+func tagOptions(tag reflect.StructTag) (map[string]bool, []int, error) {
+	options := make(map[string]bool)
+	tagValues := strings.Split(tag.Get("gosqueeze"), ",")
+	if len(tagValues) > 2 {
+		for _, o := range tagValues[2:] {
+			switch o {
+			case "readonly", "secret":
+				options[o] = true
+			default:
+				return nil, nil, errors.New("Unknown tag option " + o)
+			}
+		}
+	}
+
+	var values []int
+	if v := tag.Get("values"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, nil, errors.New("Value is not a number: " + err.Error())
+			}
+			values = append(values, n)
+		}
+	}
+	return options, values, nil
+}
+
+// resolveKind returns the supported underlying type of a field type,
+// following locally declared types, and the local type name if any.
+func resolveKind(types map[string]ast.Expr, expr ast.Expr) (string, string, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool", "string", "uint8":
+			return t.Name, "", nil
+		case "byte":
+			return "uint8", "", nil
+		}
+		underlying, ok := types[t.Name]
+		if !ok {
+			return "", "", errors.New("Unsupported type " + t.Name)
+		}
+		kind, _, err := resolveKind(types, underlying)
+		return kind, t.Name, err
+
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "net" && t.Sel.Name == "IP" {
+			return "net.IP", "", nil
+		}
+
+	case *ast.ArrayType:
+		if elt, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (elt.Name == "byte" || elt.Name == "uint8") {
+			return "[]byte", "", nil
+		}
+	}
+	return "", "", fmt.Errorf("Unsupported type %T", expr)
+}
+
+// generate returns the formatted source of the codec.
+func generate(pkgName string, typeName string, registry string, fields []field) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"datacodec -type %s -registry %s\"; DO NOT EDIT.\n\n", typeName, registry)
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	fmt.Fprintf(&b, "import \"github.com/jcrummy/gosqueeze/internal/util\"\n\n")
+
+	fmt.Fprintf(&b, "// %s describes each %s configuration value.\n", registry, typeName)
+	fmt.Fprintf(&b, "var %s = []Field{\n", registry)
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\t{\n")
+		fmt.Fprintf(&b, "\t\tName: %q,\n", f.Name)
+		fmt.Fprintf(&b, "\t\tOffset: %d,\n", f.Offset)
+		fmt.Fprintf(&b, "\t\tLength: %d,\n", f.Length)
+		fmt.Fprintf(&b, "\t\tType: %s,\n", fieldTypes[f.kind])
+		if f.readOnly {
+			fmt.Fprintf(&b, "\t\tReadOnly: true,\n")
+		}
+		if f.secret {
+			fmt.Fprintf(&b, "\t\tSecret: true,\n")
+		}
+		if f.values != nil {
+			var vs []string
+			for _, v := range f.values {
+				vs = append(vs, strconv.Itoa(v))
+			}
+			fmt.Fprintf(&b, "\t\tValues: []uint8{%s},\n", strings.Join(vs, ", "))
+		}
+		fmt.Fprintf(&b, "\t\tDescription: %q,\n", f.description)
+		fmt.Fprintf(&b, "\t},\n")
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	sorted := append([]field(nil), fields...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	fmt.Fprintf(&b, "// encodeField returns the raw value of d stored at offset, or nil if\n")
+	fmt.Fprintf(&b, "// the offset is unknown.\n")
+	fmt.Fprintf(&b, "func (d *%s) encodeField(offset int) []byte {\n", typeName)
+	fmt.Fprintf(&b, "\tswitch offset {\n")
+	for _, f := range sorted {
+		fmt.Fprintf(&b, "\tcase %d:\n", f.Offset)
+		switch f.kind {
+		case "bool":
+			fmt.Fprintf(&b, "\t\treturn util.PackBool(bool(d.%s), %d)\n", f.Name, f.Length)
+		case "string":
+			fmt.Fprintf(&b, "\t\treturn util.PackString(string(d.%s), %d)\n", f.Name, f.Length)
+		case "uint8":
+			fmt.Fprintf(&b, "\t\treturn util.PackUint8(uint8(d.%s), %d)\n", f.Name, f.Length)
+		case "net.IP":
+			fmt.Fprintf(&b, "\t\treturn util.PackBytes(d.%s.To4(), %d)\n", f.Name, f.Length)
+		case "[]byte":
+			fmt.Fprintf(&b, "\t\treturn util.PackBytes(d.%s, %d)\n", f.Name, f.Length)
+		}
+	}
+	fmt.Fprintf(&b, "\t}\n\treturn nil\n}\n\n")
+
+	fmt.Fprintf(&b, "// decodeField sets the value of d stored at offset from raw data. It\n")
+	fmt.Fprintf(&b, "// reports whether the offset is known.\n")
+	fmt.Fprintf(&b, "func (d *%s) decodeField(offset int, data []byte) (bool, error) {\n", typeName)
+	fmt.Fprintf(&b, "\tswitch offset {\n")
+	for _, f := range sorted {
+		fmt.Fprintf(&b, "\tcase %d:\n", f.Offset)
+		conv := func(v string) string {
+			if f.typeName == "" {
+				return v
+			}
+			return f.typeName + "(" + v + ")"
+		}
+		switch f.kind {
+		case "bool":
+			fmt.Fprintf(&b, "\t\tv, err := util.UnpackBool(data)\n")
+			fmt.Fprintf(&b, "\t\tif err != nil {\n\t\t\treturn true, err\n\t\t}\n")
+			fmt.Fprintf(&b, "\t\td.%s = %s\n", f.Name, conv("v"))
+		case "uint8":
+			fmt.Fprintf(&b, "\t\tv, err := util.UnpackUint8(data)\n")
+			fmt.Fprintf(&b, "\t\tif err != nil {\n\t\t\treturn true, err\n\t\t}\n")
+			fmt.Fprintf(&b, "\t\td.%s = %s\n", f.Name, conv("v"))
+		case "string":
+			fmt.Fprintf(&b, "\t\td.%s = %s\n", f.Name, conv("util.UnpackString(data)"))
+		case "net.IP", "[]byte":
+			fmt.Fprintf(&b, "\t\td.%s = util.UnpackBytes(data)\n", f.Name)
+		}
+	}
+	fmt.Fprintf(&b, "\tdefault:\n\t\treturn false, nil\n\t}\n\treturn true, nil\n}\n")
+
+	return format.Source(b.Bytes())
+}
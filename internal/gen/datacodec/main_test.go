@@ -0,0 +1,40 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedCodecUpToDate fails if devicedata_codec.go does not match
+// what go generate would write for the current DeviceData.
+func TestGeneratedCodecUpToDate(t *testing.T) {
+	const (
+		dir    = "../../.."
+		output = "devicedata_codec.go"
+	)
+	types, pkgName, err := parseTypes(dir, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := structFields(types, "DeviceData")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate(pkgName, "DeviceData", "Fields", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is stale, run go generate", output)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The seed corpus in testdata/fuzz holds discovery, get IP, get data
+// and set data replies laid out as a Receiver sends them.
+
+func FuzzParse(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		p, err := Parse(buf)
+		if err != nil {
+			return
+		}
+		// A zero UDAP type is replaced by the default when assembled
+		if p.UdapType == 0 {
+			return
+		}
+		if got := p.Assemble(); !bytes.Equal(got, buf) {
+			t.Errorf("Assemble(Parse(b)) = % x, want % x", got, buf)
+		}
+	})
+}
+
+func FuzzParseFields(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fields, err := Packet{Data: data}.ParseFields()
+		if err != nil {
+			return
+		}
+		total := 0
+		for _, v := range fields {
+			total += len(v)
+		}
+		if total > len(data) {
+			t.Errorf("values hold %d bytes, more than the %d bytes of data", total, len(data))
+		}
+	})
+}
+
+func FuzzParseData(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		total := 0
+		Packet{Data: data}.ParseData(func(offset int, value []byte) (bool, error) {
+			total += len(value)
+			return true, nil
+		})
+		if total > len(data) {
+			t.Errorf("values hold %d bytes, more than the %d bytes of data", total, len(data))
+		}
+	})
+}
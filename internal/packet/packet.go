@@ -9,12 +9,15 @@ import (
 	"errors"
 	"log"
 	"net"
-	"reflect"
 
 	"github.com/jcrummy/gosqueeze/internal/constants"
 	"github.com/jcrummy/gosqueeze/internal/util"
 )
 
+// ErrTruncated is returned when packet data ends before all of the
+// values it declares.
+var ErrTruncated = errors.New("Packet data truncated")
+
 // Packet represents a SqueezeBox configuration packet. The same
 // format is used for requests and replies.
 type Packet struct {
@@ -33,10 +36,13 @@ type Packet struct {
 	UcpFlags     byte
 	UapClass     []byte
 	UcpMethod    int
+	Credentials  []byte
 	Data         []byte
 }
 
-// Parse returns a packet struct from the raw byte slice.
+// Parse returns a packet struct from the raw byte slice. Get and set
+// data requests too short to hold their credentials are rejected with
+// ErrTruncated.
 func Parse(buf []byte) (*Packet, error) {
 	if len(buf) < 27 {
 		return nil, errors.New("Packet length too short")
@@ -45,6 +51,9 @@ func Parse(buf []byte) (*Packet, error) {
 	var p Packet
 	i := 0
 
+	if buf[i] > 1 {
+		return nil, errors.New("Invalid destination broadcast flag")
+	}
 	p.DstBroadcast = buf[i] == 1
 	i++
 	p.DstAddrType = int(buf[i])
@@ -65,6 +74,9 @@ func Parse(buf []byte) (*Packet, error) {
 	}
 	i += 6
 
+	if buf[i] > 1 {
+		return nil, errors.New("Invalid source broadcast flag")
+	}
 	p.SrcBroadcast = buf[i] == 1
 	i++
 	p.SrcAddrType = int(buf[i])
@@ -100,6 +112,15 @@ func Parse(buf []byte) (*Packet, error) {
 	p.UcpMethod = int(binary.BigEndian.Uint16(buf[i : i+2]))
 	i += 2
 
+	// Get and set data requests carry credentials ahead of the data
+	if p.hasCredentials() {
+		if len(buf) < i+len(constants.DefaultCredentials) {
+			return nil, ErrTruncated
+		}
+		p.Credentials = buf[i : i+len(constants.DefaultCredentials)]
+		i += len(constants.DefaultCredentials)
+	}
+
 	// Remaining data is returned as-is
 	p.Data = buf[i:]
 
@@ -107,6 +128,9 @@ func Parse(buf []byte) (*Packet, error) {
 }
 
 // Assemble provides a raw byte slice ready to send over the network.
+// Header fields are written as given, except that a zero UdapType or a
+// nil UapClass is replaced by the UCP default, so a packet returned by
+// Parse assembles back to the bytes it was parsed from.
 func (p Packet) Assemble() []byte {
 	var buf []byte
 	portSlice := make([]byte, 2)
@@ -145,25 +169,75 @@ func (p Packet) Assemble() []byte {
 		buf = append(buf, portSlice...)
 	}
 
-	buf = append(buf, []byte{0x00, 0x01}...)
-	buf = append(buf, constants.UdapTypeUCP...)
-	buf = append(buf, []byte{0x01}...)
-	buf = append(buf, constants.UapClassUCP...)
-	buf = append(buf, 0x00, byte(p.UcpMethod))
+	seqSlice := make([]byte, 2)
+	binary.BigEndian.PutUint16(seqSlice, uint16(p.Seq))
+	buf = append(buf, seqSlice...)
 
-	switch p.UcpMethod {
-	case constants.UCPMethodGetData:
-		buf = append(buf, constants.DefaultCredentials...)
-		buf = append(buf, p.Data...)
+	if p.UdapType == 0 {
+		buf = append(buf, constants.UdapTypeUCP...)
+	} else {
+		typeSlice := make([]byte, 2)
+		binary.BigEndian.PutUint16(typeSlice, uint16(p.UdapType))
+		buf = append(buf, typeSlice...)
+	}
 
-	case constants.UCPMethodSetData:
-		buf = append(buf, constants.DefaultCredentials...)
-		buf = append(buf, p.Data...)
+	buf = append(buf, p.UcpFlags)
+
+	if p.UapClass == nil {
+		buf = append(buf, constants.UapClassUCP...)
+	} else {
+		buf = append(buf, p.UapClass...)
+	}
+
+	methodSlice := make([]byte, 2)
+	binary.BigEndian.PutUint16(methodSlice, uint16(p.UcpMethod))
+	buf = append(buf, methodSlice...)
+
+	if p.hasCredentials() {
+		if p.Credentials == nil {
+			buf = append(buf, constants.DefaultCredentials...)
+		} else {
+			buf = append(buf, p.Credentials...)
+		}
 	}
+	buf = append(buf, p.Data...)
 
 	return buf
 }
 
+// IsRequest reports whether the packet is a request sent to a device,
+// as opposed to a reply from one. Requests are addressed to a hardware
+// address from a UDP source; replies are the reverse.
+func (p Packet) IsRequest() bool {
+	return p.DstAddrType == constants.AddrTypeEth && p.SrcAddrType == constants.AddrTypeUDP
+}
+
+// hasCredentials reports whether the packet carries credentials ahead
+// of its data. Only get and set data requests do.
+func (p Packet) hasCredentials() bool {
+	if !p.IsRequest() {
+		return false
+	}
+	return p.UcpMethod == constants.UCPMethodGetData || p.UcpMethod == constants.UCPMethodSetData
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It is equivalent
+// to Assemble.
+func (p Packet) MarshalBinary() ([]byte, error) {
+	return p.Assemble(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It is
+// equivalent to Parse, but copies data so it may be reused afterwards.
+func (p *Packet) UnmarshalBinary(data []byte) error {
+	parsed, err := Parse(append([]byte(nil), data...))
+	if err != nil {
+		return err
+	}
+	*p = *parsed
+	return nil
+}
+
 // Fields is a map of configuration data points in their raw format.
 type Fields map[byte][]byte
 
@@ -188,7 +262,7 @@ func (p Packet) ParseFields() (Fields, error) {
 			break
 		}
 		if len(buf) < length+2 {
-			return nil, errors.New("Data buffer too short")
+			return nil, ErrTruncated
 		}
 		data[ucpCode] = buf[2 : length+2]
 		buf = buf[length+2:]
@@ -197,16 +271,11 @@ func (p Packet) ParseFields() (Fields, error) {
 	return data, nil
 }
 
-// ParseData populates a struct based on the .Data byte slice
-// of the packet. Field data is entered based on the tagged offset
-// value of the structure.
-func (p Packet) ParseData(dataFields interface{}) error {
-	fieldOffsets := util.GetOffsetMap(dataFields)
-	s := reflect.ValueOf(dataFields).Elem()
-	if s.Kind() != reflect.Struct {
-		return errors.New("Not a structure")
-	}
-
+// ParseData passes each configuration value in the .Data byte slice
+// of the packet to decode, along with the offset it is stored at.
+// Decode reports whether the offset is known; unknown offsets are
+// logged and skipped.
+func (p Packet) ParseData(decode func(offset int, data []byte) (bool, error)) error {
 	buf := p.Data
 	if len(buf) < 2 {
 		return errors.New("No data")
@@ -215,89 +284,58 @@ func (p Packet) ParseData(dataFields interface{}) error {
 	buf = buf[2:]
 	for i := 0; i < numValues; i++ {
 		if len(buf) < 4 {
-			break
+			return ErrTruncated
 		}
-		index := int(binary.BigEndian.Uint16(buf[0:2]))
+		offset := int(binary.BigEndian.Uint16(buf[0:2]))
 		length := int(binary.BigEndian.Uint16(buf[2:4]))
 		if len(buf) < 4+length {
-			return errors.New("Data format error")
-		}
-		name, ok := fieldOffsets[index]
-		if !ok {
-			log.Printf("Field not found for offset %d.\n", index)
-			continue
-		}
-		f := s.FieldByName(name)
-		if !f.IsValid() {
-			log.Printf("Field not found for offset %d: %s.\n", index, name)
-			continue
-		}
-		if !f.CanSet() {
-			log.Printf("Can't set this data for %+v\n", name)
-			buf = buf[4+length:]
-			continue
+			return ErrTruncated
 		}
 		data := buf[4 : 4+length]
+		buf = buf[4+length:]
 
-		switch f.Type().String() {
-		case "bool":
-			f.SetBool(data[0] == 0x01)
-
-		case "string":
-			f.SetString(string(data))
-
-		case "uint8":
-			f.SetUint(uint64(data[0]))
-
-		case "net.IP":
-			f.SetBytes(data)
+		known, err := decode(offset, data)
+		if !known {
+			log.Printf("Field not found for offset %d.\n", offset)
+			continue
+		}
+		if err != nil {
+			log.Printf("Can't set data for offset %d: %s\n", offset, err.Error())
 		}
-		buf = buf[4+length:]
 	}
 
 	return nil
 }
 
-// SetDataRetrieve applies the set of configuration values to be retrieved
-func (p *Packet) SetDataRetrieve(dataFields interface{}) error {
-	st := reflect.TypeOf(dataFields)
-	p.Data = make([]byte, 2)
-	binary.BigEndian.PutUint16(p.Data, uint16(st.NumField()))
-	for i := 0; i < st.NumField(); i++ {
-		field := st.Field(i)
-		offset, length, err := util.GetTag(field)
-		if err != nil {
-			continue
-		}
-		offsetB := make([]byte, 2)
-		lengthB := make([]byte, 2)
-		binary.BigEndian.PutUint16(offsetB, uint16(offset))
-		binary.BigEndian.PutUint16(lengthB, uint16(length))
-		p.Data = append(p.Data, offsetB...)
-		p.Data = append(p.Data, lengthB...)
+// RetrieveData returns the payload of a request for the configuration
+// values described by fields.
+func RetrieveData(fields []util.Field) []byte {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(len(fields)))
+	for _, field := range fields {
+		data = append(data, fieldHeader(field)...)
 	}
-	return nil
+	return data
 }
 
-// SetDataForSave applies configuration data to save to the device
-func (p *Packet) SetDataForSave(dataFields interface{}) int {
-	st := reflect.TypeOf(dataFields)
-	p.Data = make([]byte, 2)
-	binary.BigEndian.PutUint16(p.Data, uint16(st.NumField()))
-	for i := 0; i < st.NumField(); i++ {
-		field := st.Field(i)
-		offset, length, err := util.GetTag(field)
-		if err != nil {
-			continue
-		}
-		offsetB := make([]byte, 2)
-		lengthB := make([]byte, 2)
-		binary.BigEndian.PutUint16(offsetB, uint16(offset))
-		binary.BigEndian.PutUint16(lengthB, uint16(length))
-		p.Data = append(p.Data, offsetB...)
-		p.Data = append(p.Data, lengthB...)
-		fieldValue := reflect.ValueOf(dataFields).FieldByName(field.Name)
-		p.Data = append(p.Data, util.Pack(fieldValue.Interface(), length)...)
+// SaveData returns the payload of a request saving the configuration
+// values described by fields. Encode returns the raw value stored at
+// an offset.
+func SaveData(fields []util.Field, encode func(offset int) []byte) []byte {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(len(fields)))
+	for _, field := range fields {
+		data = append(data, fieldHeader(field)...)
+		data = append(data, encode(field.Offset)...)
 	}
-	return st.NumField()
+	return data
+}
+
+// fieldHeader returns the offset and length of a field as they
+// precede its value in get and set data payloads.
+func fieldHeader(field util.Field) []byte {
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint16(header[0:2], uint16(field.Offset))
+	binary.BigEndian.PutUint16(header[2:4], uint16(field.Length))
+	return header
 }
@@ -0,0 +1,166 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// header returns a raw packet header: destination, source, sequence
+// number, UDAP type, UCP flags, UAP class and UCP method.
+func header(dst, src []byte, seq, udapType []byte, flags byte, class []byte, method byte) []byte {
+	var buf []byte
+	buf = append(buf, dst...)
+	buf = append(buf, src...)
+	buf = append(buf, seq...)
+	buf = append(buf, udapType...)
+	buf = append(buf, flags)
+	buf = append(buf, class...)
+	buf = append(buf, 0x00, method)
+	return buf
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+var (
+	ucpType  = []byte{0xC0, 0x01}
+	ucpClass = []byte{0x00, 0x01, 0x00, 0x01}
+	seqOne   = []byte{0x00, 0x01}
+
+	// Requests are sent to a hardware address from a UDP source
+	ethBroadcast = []byte{0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	ethReceiver  = []byte{0x00, 0x01, 0x00, 0x04, 0x20, 0x16, 0x02, 0x9a}
+	udpAny       = []byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	// Replies are sent to a UDP address from a hardware address
+	udpClient   = []byte{0x00, 0x02, 0xc0, 0xa8, 0x01, 0x0a, 0xd6, 0x8c}
+	ethReplying = []byte{0x00, 0x01, 0x00, 0x04, 0x20, 0x16, 0x02, 0x9a}
+
+	credentials = bytes.Repeat([]byte{0x00}, 32)
+	password    = append([]byte("secret"), bytes.Repeat([]byte{0x00}, 26)...)
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"discovery request", header(ethBroadcast, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x01)},
+		{"discovery reply", join(header(udpClient, ethReplying, seqOne, ucpType, 0x00, ucpClass, 0x01),
+			[]byte{0x02, 0x0d}, []byte("SqueezeboxRcv"),
+			[]byte{0x03, 0x08}, []byte("receiver"),
+			[]byte{0x09, 0x02, 0x00, 0x4d},
+			[]byte{0x0a, 0x04, 0x00, 0x00, 0x00, 0x01},
+			[]byte{0x0b, 0x02, 0x00, 0x07},
+			[]byte{0x0c, 0x09}, []byte("connected"))},
+		{"get data request", join(header(ethReceiver, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x05),
+			credentials,
+			[]byte{0x00, 0x02, 0x00, 0x04, 0x00, 0x01, 0x00, 0x11, 0x00, 0x21})},
+		{"get data request with password", join(header(ethReceiver, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x05),
+			password,
+			[]byte{0x00, 0x01, 0x00, 0x04, 0x00, 0x01})},
+		{"set data request", join(header(ethReceiver, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x06),
+			password,
+			[]byte{0x00, 0x01, 0x00, 0x04, 0x00, 0x01, 0x01})},
+		{"get data reply", join(header(udpClient, ethReplying, seqOne, ucpType, 0x00, ucpClass, 0x05),
+			[]byte{0x00, 0x01, 0x00, 0x04, 0x00, 0x01, 0x01})},
+		{"set data reply", join(header(udpClient, ethReplying, seqOne, ucpType, 0x00, ucpClass, 0x06),
+			[]byte{0x00, 0x01})},
+		{"non-default header", join(header(ethReceiver, []byte{0x01, 0x02, 0x0a, 0x00, 0x00, 0x02, 0x45, 0x78},
+			[]byte{0xbe, 0xef}, []byte{0x12, 0x34}, 0x80, []byte{0xde, 0xad, 0xbe, 0xef}, 0x05),
+			credentials,
+			[]byte{0x00, 0x00})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.buf)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := p.Assemble(); !bytes.Equal(got, tt.buf) {
+				t.Errorf("Assemble(Parse(b)) = % x, want % x", got, tt.buf)
+			}
+
+			var q Packet
+			if err := q.UnmarshalBinary(tt.buf); err != nil {
+				t.Fatalf("UnmarshalBinary: %v", err)
+			}
+			if got, _ := q.MarshalBinary(); !bytes.Equal(got, tt.buf) {
+				t.Errorf("MarshalBinary(UnmarshalBinary(b)) = % x, want % x", got, tt.buf)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	buf := join(header(ethReceiver, []byte{0x01, 0x02, 0x0a, 0x00, 0x00, 0x02, 0x45, 0x78},
+		[]byte{0xbe, 0xef}, []byte{0x12, 0x34}, 0x80, []byte{0xde, 0xad, 0xbe, 0xef}, 0x06),
+		password,
+		[]byte{0x00, 0x00})
+	p, err := Parse(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Seq != 0xbeef || p.UdapType != 0x1234 || p.UcpFlags != 0x80 || !bytes.Equal(p.UapClass, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("header = %d %#x %#x % x", p.Seq, p.UdapType, p.UcpFlags, p.UapClass)
+	}
+	if !p.SrcBroadcast || p.SrcPort != 17784 || p.SrcIP.String() != "10.0.0.2" {
+		t.Errorf("source = %v %v:%d", p.SrcBroadcast, p.SrcIP, p.SrcPort)
+	}
+	if !bytes.Equal(p.Credentials, password) {
+		t.Errorf("Credentials = % x, want % x", p.Credentials, password)
+	}
+	if !bytes.Equal(p.Data, []byte{0x00, 0x00}) {
+		t.Errorf("Data = % x", p.Data)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		want error
+	}{
+		{"short header", ethBroadcast, nil},
+		{"invalid broadcast flag", header([]byte{0x02, 0x01, 0, 0, 0, 0, 0, 0}, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x01), nil},
+		{"unknown destination type", header([]byte{0x00, 0x03, 0, 0, 0, 0, 0, 0}, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x01), nil},
+		{"unknown source type", header(ethBroadcast, []byte{0x00, 0x00, 0, 0, 0, 0, 0, 0}, seqOne, ucpType, 0x01, ucpClass, 0x01), nil},
+		{"get data request without credentials", header(ethReceiver, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x05), ErrTruncated},
+		{"set data request with short credentials", join(header(ethReceiver, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x06),
+			credentials[:31]), ErrTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.buf)
+			if err == nil {
+				t.Fatal("Parse succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Parse error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembleDefaults(t *testing.T) {
+	p := Packet{
+		DstAddrType: 1,
+		DstMac:      ethReceiver[2:],
+		SrcAddrType: 2,
+		SrcIP:       []byte{0, 0, 0, 0},
+		Seq:         1,
+		UcpFlags:    0x01,
+		UcpMethod:   5,
+		Data:        []byte{0x00, 0x00},
+	}
+	want := join(header(ethReceiver, udpAny, seqOne, ucpType, 0x01, ucpClass, 0x05), credentials, []byte{0x00, 0x00})
+	if got := p.Assemble(); !bytes.Equal(got, want) {
+		t.Errorf("Assemble() = % x, want % x", got, want)
+	}
+}
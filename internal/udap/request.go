@@ -0,0 +1,162 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package udap
+
+import (
+	"bytes"
+	"errors"
+	"net"
+
+	"github.com/jcrummy/gosqueeze/internal/constants"
+	"github.com/jcrummy/gosqueeze/internal/packet"
+)
+
+// Request builds a UDAP request packet. Each setter returns the request
+// so calls can be chained, and the combination of values is validated
+// when the packet is built.
+type Request struct {
+	p packet.Packet
+}
+
+// NewRequest returns a request for the given UCP method. By default the
+// request is broadcast to all devices from an unspecified UDP source,
+// with sequence number 1 and the standard UCP type, flags and class.
+func NewRequest(method int) *Request {
+	return &Request{p: packet.Packet{
+		DstBroadcast: true,
+		DstAddrType:  constants.AddrTypeEth,
+		DstMac:       constants.MacZero,
+		SrcBroadcast: false,
+		SrcAddrType:  constants.AddrTypeUDP,
+		SrcIP:        constants.IPZero,
+		SrcPort:      0,
+		Seq:          1,
+		UdapType:     constants.UdapTypeUCPValue,
+		UcpFlags:     constants.UcpFlagsRequest,
+		UapClass:     constants.UapClassUCP,
+		UcpMethod:    method,
+	}}
+}
+
+// To addresses the request to the device with the given hardware address.
+// The address is copied, so later changes to mac do not affect the request.
+func (r *Request) To(mac net.HardwareAddr) *Request {
+	r.p.DstBroadcast = false
+	r.p.DstAddrType = constants.AddrTypeEth
+	r.p.DstMac = append(net.HardwareAddr(nil), mac...)
+	return r
+}
+
+// From sets the UDP source address of the request. The address is
+// stored in its 4-byte form; addresses that are not IPv4 are rejected
+// when the packet is built.
+func (r *Request) From(ip net.IP, port uint) *Request {
+	r.p.SrcAddrType = constants.AddrTypeUDP
+	r.p.SrcIP = append(net.IP(nil), ip.To4()...)
+	r.p.SrcPort = port
+	return r
+}
+
+// Seq sets the sequence number of the request.
+func (r *Request) Seq(n int) *Request {
+	r.p.Seq = n
+	return r
+}
+
+// Type sets the UDAP type of the request.
+func (r *Request) Type(t int) *Request {
+	r.p.UdapType = t
+	return r
+}
+
+// Flags sets the UCP flags of the request.
+func (r *Request) Flags(f byte) *Request {
+	r.p.UcpFlags = f
+	return r
+}
+
+// Class sets the UAP class of the request.
+func (r *Request) Class(c []byte) *Request {
+	r.p.UapClass = c
+	return r
+}
+
+// Credentials sets the credentials sent with get and set data requests.
+func (r *Request) Credentials(c []byte) *Request {
+	r.p.Credentials = c
+	return r
+}
+
+// Payload sets the data carried by the request.
+func (r *Request) Payload(data []byte) *Request {
+	r.p.Data = data
+	return r
+}
+
+// Packet validates the request and returns the resulting packet.
+func (r *Request) Packet() (*packet.Packet, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	p := r.p
+	return &p, nil
+}
+
+// Assemble validates the request and returns it as a raw byte slice
+// ready to send over the network.
+func (r *Request) Assemble() ([]byte, error) {
+	p, err := r.Packet()
+	if err != nil {
+		return nil, err
+	}
+	return p.Assemble(), nil
+}
+
+// validate checks that the header fields and payload of the request
+// are consistent with each other.
+func (r *Request) validate() error {
+	p := r.p
+	if p.UcpMethod <= constants.UCPMethodZero || p.UcpMethod > constants.UCPMethodGetUUID {
+		return errors.New("Unknown UCP method")
+	}
+	if len(p.DstMac) != 6 {
+		return errors.New("Destination hardware address must be 6 bytes")
+	}
+	if !p.DstBroadcast && bytes.Equal(p.DstMac, constants.MacZero) {
+		return errors.New("Destination hardware address required")
+	}
+	if len(p.SrcIP) != 4 {
+		return errors.New("Source address must be an IPv4 address")
+	}
+	if p.SrcPort > 0xFFFF {
+		return errors.New("Source port out of range")
+	}
+	if p.Seq < 0 || p.Seq > 0xFFFF {
+		return errors.New("Sequence number out of range")
+	}
+	if p.UdapType < 0 || p.UdapType > 0xFFFF {
+		return errors.New("UDAP type out of range")
+	}
+	if len(p.UapClass) != 4 {
+		return errors.New("UAP class must be 4 bytes")
+	}
+
+	dataMethod := p.UcpMethod == constants.UCPMethodGetData || p.UcpMethod == constants.UCPMethodSetData
+	if p.Credentials != nil {
+		if !dataMethod {
+			return errors.New("Credentials are only sent with get and set data requests")
+		}
+		if len(p.Credentials) != len(constants.DefaultCredentials) {
+			return errors.New("Credentials must be 32 bytes")
+		}
+	}
+	if dataMethod && len(p.Data) < 2 {
+		return errors.New("Get and set data requests require a payload")
+	}
+	if p.DstBroadcast && p.UcpMethod == constants.UCPMethodSetData {
+		return errors.New("Set data requests must be addressed to a single device")
+	}
+	return nil
+}
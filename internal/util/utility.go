@@ -5,13 +5,20 @@
 package util
 
 import (
+	"bytes"
 	"errors"
-	"net"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
+// Field describes a gosqueeze-tagged struct field.
+type Field struct {
+	Name   string
+	Offset int
+	Length int
+}
+
 // GetTag returns gosqueeze-tagged fields from a struct field
 func GetTag(field reflect.StructField) (int, int, error) {
 	itemTag := field.Tag.Get("gosqueeze")
@@ -33,65 +40,60 @@ func GetTag(field reflect.StructField) (int, int, error) {
 	return offset, length, nil
 }
 
-// getFieldOffsets returns gosqueeze-tagged struct field information
-func getFieldOffsets(field reflect.StructField) (int, string, error) {
-	itemTag := field.Tag.Get("gosqueeze")
-	if itemTag == "" {
-		return 0, "", errors.New("No tag attached to struct field")
-	}
-	tagValues := strings.Split(itemTag, ",")
-	if len(tagValues) < 2 {
-		return 0, "", errors.New("Field tag requires two values (offset,length)")
-	}
-	offset, err := strconv.Atoi(tagValues[0])
-	if err != nil {
-		return 0, "", errors.New("Offset is not a number: " + err.Error())
+// PackBool returns a bool as a byte slice of a specific length
+func PackBool(v bool, length int) []byte {
+	slice := make([]byte, length)
+	if v && length > 0 {
+		slice[0] = 1
 	}
-	return offset, field.Name, nil
+	return slice
 }
 
-// GetOffsetMap returns gosqueeze-tagged struct data
-func GetOffsetMap(dataFields interface{}) map[int]string {
-	ret := make(map[int]string)
-	st := reflect.TypeOf(dataFields)
-	for i := 0; i < st.Elem().NumField(); i++ {
-		offset, name, err := getFieldOffsets(st.Elem().Field(i))
-		if err != nil {
-			continue
-		}
-		ret[offset] = name
-	}
-	return ret
+// PackString returns a string as a zero-padded byte slice of a specific length
+func PackString(v string, length int) []byte {
+	slice := make([]byte, length)
+	copy(slice, v)
+	return slice
 }
 
-// Pack transforms an interface to a byte slice of a specific length
-func Pack(v interface{}, length int) []byte {
-	s := reflect.TypeOf(v)
+// PackUint8 returns a uint8 as a byte slice of a specific length
+func PackUint8(v uint8, length int) []byte {
 	slice := make([]byte, length)
-	switch s.String() {
-	case "bool":
-		if v.(bool) {
-			slice[0] = 1
-			break
-		}
-		slice[0] = 0
+	if length > 0 {
+		slice[0] = v
+	}
+	return slice
+}
 
-	case "string":
-		copy(slice, []byte(v.(string)))
+// PackBytes returns a byte slice zero-padded or cut to a specific length
+func PackBytes(v []byte, length int) []byte {
+	slice := make([]byte, length)
+	copy(slice, v)
+	return slice
+}
 
-	case "uint8":
-		copy(slice, []byte{v.(uint8)})
+// UnpackBool returns the bool value of raw data
+func UnpackBool(data []byte) (bool, error) {
+	if len(data) < 1 {
+		return false, errors.New("No data for bool value")
+	}
+	return data[0] == 0x01, nil
+}
 
-	case "[]uint8":
-		copy(slice, []byte(v.([]uint8)))
+// UnpackString returns the string value of raw data, without zero padding
+func UnpackString(data []byte) string {
+	return string(bytes.TrimRight(data, "\x00"))
+}
 
-	case "net.IP":
-		copy(slice, v.(net.IP))
-	}
-	if len(slice) < length {
-		slice = append(slice, make([]byte, 33-len(slice))...)
-	} else if len(slice) > length {
-		slice = slice[:length]
+// UnpackUint8 returns the uint8 value of raw data
+func UnpackUint8(data []byte) (uint8, error) {
+	if len(data) < 1 {
+		return 0, errors.New("No data for uint8 value")
 	}
-	return slice
+	return data[0], nil
+}
+
+// UnpackBytes returns a copy of raw data
+func UnpackBytes(data []byte) []byte {
+	return append([]byte(nil), data...)
 }
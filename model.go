@@ -0,0 +1,108 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import "strings"
+
+// Model describes a kind of SqueezeBox hardware and which configuration
+// values apply to it. A device matches a model by its device ID.
+type Model struct {
+	Name     string
+	ID       uint     // device ID reported in discovery
+	Firmware string   // name of the firmware image the model runs
+	Fields   []string // names of the configuration values that apply
+}
+
+// Configuration values by the hardware they need. Every device has a
+// wired interface; wireless values need a wireless interface, and
+// bridging needs both.
+var (
+	wiredFields = []string{
+		"LanIPMode", "LanNetworkAddress", "LanSubnetMask", "LanGateway",
+		"Hostname", "PrimaryDNS", "SecondaryDNS",
+		"ActiveServerAddress", "SqueezeCenterAddress", "SqueezeCenterName",
+	}
+	wirelessFields = []string{
+		"Interface", "WirelessMode", "WirelessSSID", "WirelessChannel",
+		"WirelessRegion", "WirelessKeylen", "WirelessWEPKey0", "WirelessWEPKey1",
+		"WirelessWEPKey2", "WirelessWEPKey3", "WirelessWEPOn",
+		"WirelessWPACipher", "WirelessWPAMode", "WirelessWPAOn", "WirelessWPAPSK",
+	}
+	bridgingFields = []string{"Bridging"}
+)
+
+// fieldSet joins groups of configuration value names.
+func fieldSet(groups ...[]string) []string {
+	var names []string
+	for _, g := range groups {
+		names = append(names, g...)
+	}
+	return names
+}
+
+// allFields is every configuration value, for hardware with both
+// interfaces.
+var allFields = fieldSet(wiredFields, wirelessFields, bridgingFields)
+
+// Models lists the known hardware models. Device IDs are the same as
+// those used by SqueezeCenter. Every known model has both a wired and a
+// wireless interface, so all configuration values apply to each;
+// hardware lacking one should declare a narrower set.
+var Models = []Model{
+	{Name: "Squeezebox Classic", ID: 4, Firmware: "squeezebox2", Fields: allFields},
+	{Name: "Transporter", ID: 5, Firmware: "transporter", Fields: allFields},
+	{Name: "Squeezebox Receiver", ID: 7, Firmware: "receiver", Fields: allFields},
+	{Name: "Squeezebox Boom", ID: 10, Firmware: "boom", Fields: allFields},
+}
+
+// UnknownModel is used for devices not found in Models, including those
+// whose device ID has not been discovered. No values are left out, so
+// that reading and saving such a device never silently skips one.
+var UnknownModel = Model{Name: "Unknown", Fields: allFields}
+
+// LookupModel returns the model with the given name or firmware image
+// name, matched regardless of case.
+func LookupModel(name string) (Model, bool) {
+	for _, m := range Models {
+		if strings.EqualFold(m.Name, name) || strings.EqualFold(m.Firmware, name) {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// Supports reports whether a configuration value applies to the model.
+func (m Model) Supports(f Field) bool {
+	for _, name := range m.Fields {
+		if name == f.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Model returns the hardware model of the device, as identified by the
+// device ID found in discovery.
+func (s *Sb) Model() Model {
+	for _, m := range Models {
+		if m.ID == s.ID {
+			return m
+		}
+	}
+	return UnknownModel
+}
+
+// SupportedFields returns the configuration values that apply to the
+// device's model.
+func (s *Sb) SupportedFields() []Field {
+	m := s.Model()
+	var fields []Field
+	for _, f := range Fields {
+		if m.Supports(f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import "testing"
+
+func TestModelFieldsExist(t *testing.T) {
+	for _, m := range append(Models, UnknownModel) {
+		for _, name := range m.Fields {
+			if _, ok := LookupField(name); !ok {
+				t.Errorf("%s: unknown field %s", m.Name, name)
+			}
+		}
+	}
+}
+
+func TestUnknownModelKeepsEveryField(t *testing.T) {
+	// A device whose ID was never discovered
+	s := Sb{}
+	if m := s.Model(); m.Name != UnknownModel.Name {
+		t.Fatalf("Model() = %s, want %s", m.Name, UnknownModel.Name)
+	}
+	if got := len(s.SupportedFields()); got != len(Fields) {
+		t.Errorf("SupportedFields() has %d fields, want %d", got, len(Fields))
+	}
+
+	s.fetched, _ = s.Data.MarshalBinary()
+	s.Data.WirelessSSID = "home"
+	modified := s.Modified()
+	if len(modified) != 1 || modified[0].Name != "WirelessSSID" {
+		t.Errorf("Modified() = %v, want WirelessSSID", modified)
+	}
+}
+
+func TestModelByID(t *testing.T) {
+	s := Sb{ID: 7}
+	if got := s.Model().Name; got != "Squeezebox Receiver" {
+		t.Errorf("Model() = %s, want Squeezebox Receiver", got)
+	}
+	if m, ok := LookupModel("BOOM"); !ok || m.ID != 10 {
+		t.Errorf("LookupModel(BOOM) = %v, %v", m, ok)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+	"net"
+	"sort"
+
+	"github.com/jcrummy/gosqueeze/internal/util"
+)
+
+// Snapshot holds the single byte values a device answered with during
+// a probe, keyed by offset.
+type Snapshot map[int]byte
+
+// Offsets returns the offsets in the snapshot in ascending order.
+func (snap Snapshot) Offsets() []int {
+	offsets := make([]int, 0, len(snap))
+	for o := range snap {
+		offsets = append(offsets, o)
+	}
+	sort.Ints(offsets)
+	return offsets
+}
+
+// ProbeChange is an offset whose value differs between two snapshots.
+// Answered is false on a side where the device did not answer.
+type ProbeChange struct {
+	Offset         int
+	Before, After  byte
+	BeforeAnswered bool
+	AfterAnswered  bool
+}
+
+// Diff returns the offsets whose values differ from snap in after,
+// including offsets answered in only one of them, in ascending order.
+func (snap Snapshot) Diff(after Snapshot) []ProbeChange {
+	all := make(Snapshot)
+	for o := range snap {
+		all[o] = 0
+	}
+	for o := range after {
+		all[o] = 0
+	}
+
+	var changes []ProbeChange
+	for _, o := range all.Offsets() {
+		b, bok := snap[o]
+		a, aok := after[o]
+		if bok == aok && a == b {
+			continue
+		}
+		changes = append(changes, ProbeChange{
+			Offset:         o,
+			Before:         b,
+			After:          a,
+			BeforeAnswered: bok,
+			AfterAnswered:  aok,
+		})
+	}
+	return changes
+}
+
+// probeSilentChunks is the number of chunks in a row the device may
+// leave unanswered before Probe stops.
+const probeSilentChunks = 16
+
+// ProbeOption changes how Probe runs.
+type ProbeOption func(*probeOptions)
+
+type probeOptions struct {
+	progress func(offset int, answered int)
+}
+
+// ProbeProgress calls fn after each chunk with the next offset to be
+// probed and the number of offsets answered so far.
+func ProbeProgress(fn func(offset int, answered int)) ProbeOption {
+	return func(o *probeOptions) {
+		o.progress = fn
+	}
+}
+
+// Probe requests every offset from start up to end, one byte each, in
+// requests of chunk offsets at a time, and returns the values the
+// device answered with. Chunks the device does not answer are skipped,
+// and the probe stops early once probeSilentChunks chunks in a row go
+// unanswered, as devices ignore offsets beyond their configuration. An
+// error is only returned if no chunk was answered.
+func (s *Sb) Probe(iface *net.Interface, start int, end int, chunk int, opts ...ProbeOption) (Snapshot, error) {
+	if s.MacAddr == nil {
+		return nil, errors.New("Hardware address required")
+	}
+	if start < 0 || end > 0xFFFF || start >= end || chunk <= 0 {
+		return nil, errors.New("Invalid probe range")
+	}
+
+	var o probeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	snap := make(Snapshot)
+	var lastErr error
+	silent := 0
+	for from := start; from < end && silent < probeSilentChunks; from += chunk {
+		var fields []util.Field
+		for off := from; off < from+chunk && off < end; off++ {
+			fields = append(fields, util.Field{Offset: off, Length: 1})
+		}
+		answered := len(snap)
+		err := s.getData(iface, fields, func(offset int, raw []byte) (bool, error) {
+			if len(raw) > 0 {
+				snap[offset] = raw[0]
+			}
+			return true, nil
+		})
+		if err != nil {
+			lastErr = err
+		}
+		if len(snap) > answered {
+			silent = 0
+		} else {
+			silent++
+		}
+		if o.progress != nil {
+			o.progress(from+chunk, len(snap))
+		}
+	}
+	if len(snap) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return snap, nil
+}
+
+// FieldAt returns the DeviceData field stored over offset, if any.
+func FieldAt(offset int) (Field, bool) {
+	for _, f := range Fields {
+		if offset >= f.Offset && offset < f.Offset+f.Length {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/jcrummy/gosqueeze/internal/broadcast"
+	"github.com/jcrummy/gosqueeze/internal/constants"
+	"github.com/jcrummy/gosqueeze/internal/packet"
+	"github.com/jcrummy/gosqueeze/internal/udap"
+	"github.com/jcrummy/gosqueeze/internal/util"
+)
+
+// ReadRaw retrieves length bytes of the configuration stored at offset,
+// whether or not DeviceData covers it. Values that do not overlap any
+// DeviceData field are also kept in Unknown.
+func (s *Sb) ReadRaw(iface *net.Interface, offset int, length int) ([]byte, error) {
+	if s.MacAddr == nil {
+		return nil, errors.New("Hardware address required")
+	}
+	if err := checkRawRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	unknown := make(map[int][]byte)
+	field := util.Field{Offset: offset, Length: length}
+	err := s.getData(iface, []util.Field{field}, func(o int, raw []byte) (bool, error) {
+		if o == offset {
+			value = util.UnpackBytes(raw)
+		}
+		if !knownRange(o, len(raw)) {
+			unknown[o] = util.UnpackBytes(raw)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.keepUnknown(unknown)
+	if value == nil {
+		return nil, errors.New("Device did not return the requested offset")
+	}
+	return value, nil
+}
+
+// WriteRaw saves data to the configuration at offset. It bypasses the
+// validation and read only checks of SaveData, so it should only be
+// used to experiment with offsets DeviceData does not cover.
+// ErrPartialSave is returned if the device does not save the data.
+func (s *Sb) WriteRaw(iface *net.Interface, offset int, data []byte) error {
+	if s.MacAddr == nil {
+		return errors.New("Hardware address required")
+	}
+	if err := checkRawRange(offset, len(data)); err != nil {
+		return err
+	}
+
+	field := util.Field{Offset: offset, Length: len(data)}
+	numberChanged, err := s.setData(iface, []util.Field{field}, func(int) []byte {
+		return data
+	})
+	if err != nil {
+		return err
+	}
+	if numberChanged != 1 {
+		return ErrPartialSave
+	}
+	return nil
+}
+
+// checkRawRange checks that an offset and length can be addressed in
+// get and set data requests.
+func checkRawRange(offset int, length int) error {
+	if offset < 0 || length <= 0 || offset+length > 0xFFFF {
+		return errors.New("Offset and length out of range")
+	}
+	return nil
+}
+
+// knownRange reports whether length bytes at offset overlap any
+// DeviceData field.
+func knownRange(offset int, length int) bool {
+	for _, f := range Fields {
+		if offset < f.Offset+f.Length && f.Offset < offset+length {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownRanges returns the ranges of the configuration before and
+// between DeviceData fields, up to the end of the last field.
+func unknownRanges() []util.Field {
+	sorted := append([]Field(nil), Fields...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	var ranges []util.Field
+	next := 0
+	for _, f := range sorted {
+		if f.Offset > next {
+			ranges = append(ranges, util.Field{Offset: next, Length: f.Offset - next})
+		}
+		if f.Offset+f.Length > next {
+			next = f.Offset + f.Length
+		}
+	}
+	return ranges
+}
+
+// readUnknown retrieves the ranges DeviceData does not cover into
+// Unknown. Ranges the device does not answer are left out.
+func (s *Sb) readUnknown(iface *net.Interface) error {
+	unknown := make(map[int][]byte)
+	err := s.getData(iface, unknownRanges(), func(offset int, raw []byte) (bool, error) {
+		if !knownRange(offset, len(raw)) {
+			unknown[offset] = util.UnpackBytes(raw)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.keepUnknown(unknown)
+	return nil
+}
+
+// keepUnknown merges raw values at unknown offsets into s.Unknown.
+func (s *Sb) keepUnknown(unknown map[int][]byte) {
+	if len(unknown) == 0 {
+		return
+	}
+	if s.Unknown == nil {
+		s.Unknown = make(map[int][]byte)
+	}
+	for offset, v := range unknown {
+		s.Unknown[offset] = v
+	}
+}
+
+// getData requests the given fields from the device and passes each
+// value in the reply to decode. ErrCredentials is returned if the device
+// rejects the credentials, and ErrTruncatedReply if the reply is cut short.
+func (s *Sb) getData(iface *net.Interface, fields []util.Field, decode func(int, []byte) (bool, error)) error {
+	packetBytes, err := udap.NewRequest(constants.UCPMethodGetData).
+		To(s.MacAddr).
+		Credentials(s.credentials()).
+		Payload(packet.RetrieveData(fields)).
+		Assemble()
+	if err != nil {
+		return err
+	}
+
+	var parseErr error
+	err = broadcast.BroadcastSingle(iface, constants.UdapPort, packetBytes, 500*time.Millisecond, func(n int, addr *net.UDPAddr, buf []byte) {
+		p, err := packet.Parse(buf[:n])
+		if err != nil {
+			return
+		}
+		if p.UcpMethod == constants.UCPMethodGetData {
+			parseErr = replyError(p.ParseData(decode))
+		}
+		if err := credentialsError(p.UcpMethod); err != nil {
+			parseErr = err
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return parseErr
+}
+
+// setData saves the given fields, with values returned by encode, to
+// the device. It returns the number of fields the device reports as
+// saved, or ErrCredentials if the device rejects the credentials.
+func (s *Sb) setData(iface *net.Interface, fields []util.Field, encode func(int) []byte) (int, error) {
+	packetBytes, err := udap.NewRequest(constants.UCPMethodSetData).
+		To(s.MacAddr).
+		Credentials(s.credentials()).
+		Payload(packet.SaveData(fields, encode)).
+		Assemble()
+	if err != nil {
+		return 0, err
+	}
+
+	var numberChanged int
+	var replyErr error
+	err = broadcast.BroadcastSingle(iface, constants.UdapPort, packetBytes, 500*time.Millisecond, func(n int, addr *net.UDPAddr, buf []byte) {
+		p, err := packet.Parse(buf[:n])
+		if err != nil {
+			return
+		}
+		if p.UcpMethod == constants.UCPMethodSetData {
+			if len(p.Data) < 2 {
+				replyErr = ErrTruncatedReply
+				return
+			}
+			numberChanged = int(binary.BigEndian.Uint16(p.Data))
+		}
+		if err := credentialsError(p.UcpMethod); err != nil {
+			replyErr = err
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return numberChanged, replyErr
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SecurityMode is the kind of wireless security used.
+type SecurityMode int
+
+// Wireless security modes
+const (
+	Open SecurityMode = iota
+	WEP
+	WPA
+	WPA2
+)
+
+var securityModeNames = []string{"open", "wep", "wpa", "wpa2"}
+
+func (m SecurityMode) String() string {
+	if m < 0 || int(m) >= len(securityModeNames) {
+		return "SecurityMode(" + strconv.Itoa(int(m)) + ")"
+	}
+	return securityModeNames[m]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m SecurityMode) MarshalText() ([]byte, error) {
+	if m < 0 || int(m) >= len(securityModeNames) {
+		return nil, errors.New("Unknown security mode")
+	}
+	return []byte(securityModeNames[m]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (m *SecurityMode) UnmarshalText(text []byte) error {
+	for i, name := range securityModeNames {
+		if strings.EqualFold(name, string(text)) {
+			*m = SecurityMode(i)
+			return nil
+		}
+	}
+	return errors.New("Unknown security mode " + string(text))
+}
+
+// Security describes the wireless security settings of a device.
+type Security struct {
+	Mode   SecurityMode
+	Cipher WPACipher // WPA and WPA2 only; defaults to TKIP for WPA and AES for WPA2
+	PSK    string    // WPA and WPA2 only
+	Keys   []WEPKey  // WEP only; one to four keys of the same size
+}
+
+// SetWirelessSecurity sets every field related to wireless security
+// consistently for the given settings, so that only one kind of
+// security is on. Keys and passphrases of modes being turned off are
+// left as they are.
+func (d *DeviceData) SetWirelessSecurity(s Security) error {
+	switch s.Mode {
+	case Open:
+		d.WirelessWEPOn = false
+		d.disableWPA()
+
+	case WEP:
+		if len(s.Keys) == 0 || len(s.Keys) > 4 {
+			return errors.New("WEP requires one to four keys")
+		}
+		size := len(s.Keys[0])
+		if size != WEPKeySize64 && size != WEPKeySize128 {
+			return errors.New("WEP keys must be 5 or 13 bytes")
+		}
+		for _, k := range s.Keys {
+			if len(k) != size {
+				return errors.New("WEP keys must all be the same size")
+			}
+		}
+		keys := make([]WEPKey, 4)
+		copy(keys, s.Keys)
+		d.WirelessWEPKey0, d.WirelessWEPKey1, d.WirelessWEPKey2, d.WirelessWEPKey3 = keys[0], keys[1], keys[2], keys[3]
+		d.WirelessKeylen = 0
+		if size == WEPKeySize128 {
+			d.WirelessKeylen = 1
+		}
+		d.WirelessWEPOn = true
+		d.disableWPA()
+
+	case WPA, WPA2:
+		if !validPSK(s.PSK) {
+			return errors.New("PSK must be 8 to 63 ASCII characters or 64 hex digits")
+		}
+		cipher := s.Cipher
+		if cipher == NoCipher {
+			cipher = TKIP
+			if s.Mode == WPA2 {
+				cipher = AES
+			}
+		}
+		if !cipher.Valid() {
+			return errors.New("Unknown WPA cipher")
+		}
+		d.WirelessWEPOn = false
+		d.WirelessWPAOn = true
+		d.WirelessWPAMode = WPAModeWPA
+		if s.Mode == WPA2 {
+			d.WirelessWPAMode = WPAModeWPA2
+		}
+		d.WirelessWPACipher = cipher
+		d.WirelessWPAPSK = s.PSK
+
+	default:
+		return errors.New("Unknown security mode")
+	}
+	return nil
+}
+
+// WirelessSecurity interprets the wireless security fields. If both
+// WPA and WEP are on, WPA is reported. WEP keys keep their positions,
+// with unused keys after the last one in use left out.
+func (d *DeviceData) WirelessSecurity() Security {
+	if d.WirelessWPAOn {
+		s := Security{Mode: WPA, Cipher: d.WirelessWPACipher, PSK: d.WirelessWPAPSK}
+		if d.WirelessWPAMode == WPAModeWPA2 {
+			s.Mode = WPA2
+		}
+		return s
+	}
+	if d.WirelessWEPOn {
+		s := Security{Mode: WEP}
+		size := d.WEPKeySize()
+		keys := d.wepKeys()
+		for i, k := range keys {
+			if !k.IsZero() {
+				s.Keys = keys[:i+1]
+			}
+		}
+		for i, k := range s.Keys {
+			s.Keys[i] = k.Trim(size)
+		}
+		return s
+	}
+	return Security{Mode: Open}
+}
+
+// disableWPA turns WPA off and clears its mode and cipher.
+func (d *DeviceData) disableWPA() {
+	d.WirelessWPAOn = false
+	d.WirelessWPAMode = WPAModeNone
+	d.WirelessWPACipher = NoCipher
+}
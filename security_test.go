@@ -0,0 +1,82 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetWirelessSecurity(t *testing.T) {
+	tests := []struct {
+		name     string
+		security Security
+		ok       bool
+		want     Security // as read back by WirelessSecurity
+	}{
+		{"open", Security{Mode: Open}, true, Security{Mode: Open}},
+		{"wpa default cipher", Security{Mode: WPA, PSK: "correct horse"}, true,
+			Security{Mode: WPA, Cipher: TKIP, PSK: "correct horse"}},
+		{"wpa2 default cipher", Security{Mode: WPA2, PSK: "correct horse"}, true,
+			Security{Mode: WPA2, Cipher: AES, PSK: "correct horse"}},
+		{"wpa2 tkip+aes", Security{Mode: WPA2, Cipher: TKIPAES, PSK: strings.Repeat("ab", 32)}, true,
+			Security{Mode: WPA2, Cipher: TKIPAES, PSK: strings.Repeat("ab", 32)}},
+		{"psk too short", Security{Mode: WPA2, PSK: "short"}, false, Security{}},
+		{"psk too long", Security{Mode: WPA, PSK: strings.Repeat("x", 64)}, false, Security{}},
+		{"unknown cipher", Security{Mode: WPA, Cipher: WPACipher(9), PSK: "correct horse"}, false, Security{}},
+		{"wep 64-bit", Security{Mode: WEP, Keys: []WEPKey{WEPKey("abcde")}}, true,
+			Security{Mode: WEP, Keys: []WEPKey{WEPKey("abcde")}}},
+		{"wep 128-bit", Security{Mode: WEP, Keys: []WEPKey{WEPKey("0123456789abc"), WEPKey("abcdefghijklm")}}, true,
+			Security{Mode: WEP, Keys: []WEPKey{WEPKey("0123456789abc"), WEPKey("abcdefghijklm")}}},
+		{"wep no keys", Security{Mode: WEP}, false, Security{}},
+		{"wep five keys", Security{Mode: WEP, Keys: []WEPKey{WEPKey("abcde"), WEPKey("abcde"), WEPKey("abcde"), WEPKey("abcde"), WEPKey("abcde")}}, false, Security{}},
+		{"wep wrong size", Security{Mode: WEP, Keys: []WEPKey{WEPKey("abcdef")}}, false, Security{}},
+		{"wep mixed sizes", Security{Mode: WEP, Keys: []WEPKey{WEPKey("abcde"), WEPKey("0123456789abc")}}, false, Security{}},
+		{"unknown mode", Security{Mode: SecurityMode(7)}, false, Security{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Start from WPA so changes to every mode are seen
+			var d DeviceData
+			if err := d.SetWirelessSecurity(Security{Mode: WPA, PSK: "previous key"}); err != nil {
+				t.Fatal(err)
+			}
+			before := d
+			err := d.SetWirelessSecurity(tt.security)
+			if (err == nil) != tt.ok {
+				t.Fatalf("SetWirelessSecurity() error = %v, want ok %v", err, tt.ok)
+			}
+			if !tt.ok {
+				if !reflect.DeepEqual(d, before) {
+					t.Error("configuration changed on error")
+				}
+				return
+			}
+			if got := d.WirelessSecurity(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WirelessSecurity() = %+v, want %+v", got, tt.want)
+			}
+			if d.WirelessWEPOn && d.WirelessWPAOn {
+				t.Error("both WEP and WPA are on")
+			}
+		})
+	}
+}
+
+func TestSecurityModeText(t *testing.T) {
+	for _, m := range []SecurityMode{Open, WEP, WPA, WPA2} {
+		text, err := m.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got SecurityMode
+		if err := got.UnmarshalText([]byte(strings.ToUpper(string(text)))); err != nil || got != m {
+			t.Errorf("UnmarshalText(%s) = %v, %v", text, got, err)
+		}
+	}
+	if _, err := SecurityMode(7).MarshalText(); err == nil {
+		t.Error("MarshalText accepted an unknown mode")
+	}
+}
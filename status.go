@@ -0,0 +1,55 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import "strings"
+
+// Status is the connection state reported by a device in discovery. It
+// is refreshed by Discover and GetIP.
+type Status string
+
+// Device statuses
+const (
+	StatusInit         Status = "init"
+	StatusWaitWireless Status = "wait_wireless"
+	StatusWaitDHCP     Status = "wait_dhcp"
+	StatusWaitServer   Status = "wait_slimserver"
+	StatusConnected    Status = "connected"
+)
+
+var statusDescriptions = map[Status]string{
+	StatusInit:         "Starting up",
+	StatusWaitWireless: "Waiting to join the wireless network",
+	StatusWaitDHCP:     "Waiting for an address from DHCP",
+	StatusWaitServer:   "Waiting to connect to a server",
+	StatusConnected:    "Connected to a server",
+}
+
+// Known reports whether the status is one of the documented values.
+func (s Status) Known() bool {
+	_, ok := statusDescriptions[s]
+	return ok
+}
+
+// Description returns a human readable description of the status.
+func (s Status) Description() string {
+	if d, ok := statusDescriptions[s]; ok {
+		return d
+	}
+	return "Unknown status " + string(s)
+}
+
+// Healthy reports whether the device is connected to a server and
+// working normally.
+func (s Status) Healthy() bool {
+	return s == StatusConnected
+}
+
+// Waiting reports whether the device is waiting on the network or a
+// server. A device that stays waiting is usually misconfigured, or
+// still in setup mode.
+func (s Status) Waiting() bool {
+	return strings.HasPrefix(string(s), "wait_")
+}
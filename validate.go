@@ -0,0 +1,222 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// FieldError describes a problem with a single configuration value.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every problem found by Validate.
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e ValidationError) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// Validate checks the configuration for values the device would
+// reject or that would leave it unreachable. All problems found are
+// returned together as a ValidationError.
+func (d *DeviceData) Validate() error {
+	return d.ValidateFields(Fields)
+}
+
+// ValidateFields is like Validate, but only reports problems with the
+// given fields, such as those supported by a device's model.
+func (d *DeviceData) ValidateFields(fields []Field) error {
+	var errs ValidationError
+	if err, ok := d.validate().(ValidationError); ok {
+		for _, fe := range err {
+			for _, f := range fields {
+				if f.Name == fe.Field {
+					errs = append(errs, fe)
+					break
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate checks the configuration values supported by the device's
+// model, as DeviceData.Validate does.
+func (s *Sb) Validate() error {
+	return s.Data.ValidateFields(s.SupportedFields())
+}
+
+// validate checks every configuration value.
+func (d *DeviceData) validate() error {
+	var errs ValidationError
+	add := func(field string, format string, args ...interface{}) {
+		errs = append(errs, &FieldError{Field: field, Err: fmt.Errorf(format, args...)})
+	}
+
+	for _, f := range Fields {
+		if f.ReadOnly || f.Type != FieldUint8 {
+			continue
+		}
+		// A wired device never uses its radio, and may report no region
+		if f.Name == "WirelessRegion" && d.Interface == LinkWired {
+			continue
+		}
+		v := d.encodeField(f.Offset)[0]
+		if !f.Allowed(v) {
+			add(f.Name, "%d is not an allowed value", v)
+		}
+	}
+
+	lengths := map[string]int{
+		"Hostname":        len(d.Hostname),
+		"WirelessSSID":    len(d.WirelessSSID),
+		"WirelessWPAPSK":  len(d.WirelessWPAPSK),
+		"WirelessWEPKey0": len(d.WirelessWEPKey0),
+		"WirelessWEPKey1": len(d.WirelessWEPKey1),
+		"WirelessWEPKey2": len(d.WirelessWEPKey2),
+		"WirelessWEPKey3": len(d.WirelessWEPKey3),
+	}
+	for _, f := range Fields {
+		if n, ok := lengths[f.Name]; ok && n > f.Length {
+			add(f.Name, "longer than %d bytes", f.Length)
+		}
+	}
+
+	if d.Hostname != "" && !validHostname(d.Hostname) {
+		add("Hostname", "must be letters, digits and hyphens, not starting or ending with a hyphen")
+	}
+
+	if !d.LanIPMode {
+		d.validateStatic(add)
+	}
+
+	if d.WirelessWPAOn {
+		if d.WirelessWPAPSK == "" {
+			add("WirelessWPAPSK", "required when WPA is on")
+		}
+	}
+	if d.WirelessWPAPSK != "" && !validPSK(d.WirelessWPAPSK) {
+		add("WirelessWPAPSK", "must be 8 to 63 ASCII characters or 64 hex digits")
+	}
+
+	if d.WirelessWEPOn {
+		size := d.WEPKeySize()
+		for i, key := range d.wepKeys() {
+			name := fmt.Sprintf("WirelessWEPKey%d", i)
+			if key.IsZero() {
+				if i == 0 {
+					add(name, "required when WEP is on")
+				}
+				continue
+			}
+			if len(key.Trim(size)) != size {
+				add(name, "must be %d bytes for the selected key length", size)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateStatic checks the static network settings.
+func (d *DeviceData) validateStatic(add func(string, string, ...interface{})) {
+	addr := d.LanNetworkAddress.To4()
+	if addr == nil || addr.Equal(net.IPv4zero) {
+		add("LanNetworkAddress", "required for a static IP address")
+		return
+	}
+	mask := net.IPMask(d.LanSubnetMask.To4())
+	if mask == nil {
+		add("LanSubnetMask", "required for a static IP address")
+		return
+	}
+	ones, bits := mask.Size()
+	if bits == 0 || ones == 0 {
+		add("LanSubnetMask", "%s is not a valid subnet mask", d.LanSubnetMask)
+		return
+	}
+	subnet := net.IPNet{IP: addr.Mask(mask), Mask: mask}
+	if ones < 31 && (addr.Equal(subnet.IP) || addr.Equal(broadcastAddr(subnet))) {
+		add("LanNetworkAddress", "%s is the network or broadcast address of its subnet", addr)
+	}
+	gateway := d.LanGateway.To4()
+	if gateway != nil && !gateway.Equal(net.IPv4zero) && !subnet.Contains(gateway) {
+		add("LanGateway", "%s is not inside subnet %s", gateway, subnet.String())
+	}
+}
+
+// broadcastAddr returns the broadcast address of an IPv4 subnet.
+func broadcastAddr(n net.IPNet) net.IP {
+	ip := make(net.IP, len(n.IP))
+	for i := range n.IP {
+		ip[i] = n.IP[i] | ^n.Mask[i]
+	}
+	return ip
+}
+
+// validHostname reports whether s is a valid single-label host name.
+func validHostname(s string) bool {
+	if len(s) > 63 || strings.HasPrefix(s, "-") || strings.HasSuffix(s, "-") {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validPSK reports whether s is a WPA passphrase of 8 to 63 printable
+// ASCII characters or a raw key of 64 hex digits.
+func validPSK(s string) bool {
+	if len(s) == 64 {
+		_, err := hex.DecodeString(s)
+		return err == nil
+	}
+	if len(s) < 8 || len(s) > 63 {
+		return false
+	}
+	for _, c := range s {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// validData returns a configuration that passes Validate: a static
+// address on a wireless link with WPA.
+func validData() DeviceData {
+	return DeviceData{
+		LanNetworkAddress: net.IPv4(192, 168, 1, 20).To4(),
+		LanSubnetMask:     net.IPv4(255, 255, 255, 0).To4(),
+		LanGateway:        net.IPv4(192, 168, 1, 1).To4(),
+		Hostname:          "receiver",
+		WirelessSSID:      "home",
+		WirelessRegion:    4,
+		WirelessWPACipher: 2,
+		WirelessWPAMode:   2,
+		WirelessWPAOn:     true,
+		WirelessWPAPSK:    "correct horse",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(d *DeviceData)
+		fields []string // fields reported, nil if valid
+	}{
+		{"valid", func(d *DeviceData) {}, nil},
+		{"dhcp ignores static", func(d *DeviceData) {
+			d.LanIPMode = true
+			d.LanNetworkAddress = nil
+		}, nil},
+		{"psk too short", func(d *DeviceData) { d.WirelessWPAPSK = "short" }, []string{"WirelessWPAPSK"}},
+		{"psk too long", func(d *DeviceData) { d.WirelessWPAPSK = strings.Repeat("a", 65) }, []string{"WirelessWPAPSK", "WirelessWPAPSK"}},
+		{"psk 63 characters", func(d *DeviceData) { d.WirelessWPAPSK = strings.Repeat("a", 63) }, nil},
+		{"psk 64 hex digits", func(d *DeviceData) { d.WirelessWPAPSK = strings.Repeat("0f", 32) }, nil},
+		{"psk 64 characters not hex", func(d *DeviceData) { d.WirelessWPAPSK = strings.Repeat("xy", 32) }, []string{"WirelessWPAPSK"}},
+		{"psk not ascii", func(d *DeviceData) { d.WirelessWPAPSK = "pässwörd" }, []string{"WirelessWPAPSK"}},
+		{"psk missing", func(d *DeviceData) { d.WirelessWPAPSK = "" }, []string{"WirelessWPAPSK"}},
+		{"hostname with spaces", func(d *DeviceData) { d.Hostname = "living room" }, []string{"Hostname"}},
+		{"hostname leading hyphen", func(d *DeviceData) { d.Hostname = "-receiver" }, []string{"Hostname"}},
+		{"hostname too long", func(d *DeviceData) { d.Hostname = strings.Repeat("a", 34) }, []string{"Hostname"}},
+		{"zero mask", func(d *DeviceData) { d.LanSubnetMask = net.IPv4(0, 0, 0, 0).To4() }, []string{"LanSubnetMask"}},
+		{"missing address", func(d *DeviceData) { d.LanNetworkAddress = nil }, []string{"LanNetworkAddress"}},
+		{"broadcast address", func(d *DeviceData) { d.LanNetworkAddress = net.IPv4(192, 168, 1, 255).To4() }, []string{"LanNetworkAddress"}},
+		{"gateway outside subnet", func(d *DeviceData) { d.LanGateway = net.IPv4(10, 0, 0, 1).To4() }, []string{"LanGateway"}},
+		{"wep key too long", func(d *DeviceData) {
+			d.WirelessWEPOn = true
+			d.WirelessWEPKey0 = []byte("abcdef")
+		}, []string{"WirelessWEPKey0"}},
+		{"wep key missing", func(d *DeviceData) { d.WirelessWEPOn = true }, []string{"WirelessWEPKey0"}},
+		{"unknown region", func(d *DeviceData) { d.WirelessRegion = 5 }, []string{"WirelessRegion"}},
+		{"no region on wired link", func(d *DeviceData) {
+			d.Interface = 1
+			d.WirelessRegion = 0
+		}, nil},
+		{"unknown link", func(d *DeviceData) { d.Interface = 2 }, []string{"Interface"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := validData()
+			tt.change(&d)
+			err := d.Validate()
+			var got []string
+			var verr ValidationError
+			if errors.As(err, &verr) {
+				for _, fe := range verr {
+					got = append(got, fe.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() returned %T, want ValidationError", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("Validate() reported %v, want %v: %v", got, tt.fields, err)
+			}
+		})
+	}
+}
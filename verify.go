@@ -0,0 +1,63 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"net"
+	"strings"
+)
+
+// Mismatch is a value that did not read back from the device as it
+// was saved.
+type Mismatch struct {
+	Field    Field
+	Expected string
+	Actual   string
+}
+
+func (m Mismatch) String() string {
+	if m.Field.Secret {
+		return m.Field.Name + ": value differs from what was saved"
+	}
+	return m.Field.Name + ": expected " + m.Expected + ", read back " + m.Actual
+}
+
+// VerifyError lists every value that did not read back from the device
+// as it was saved. Secret values are not included in the message.
+type VerifyError []Mismatch
+
+func (e VerifyError) Error() string {
+	msgs := make([]string, len(e))
+	for i, m := range e {
+		msgs[i] = m.String()
+	}
+	return "Saved data could not be verified: " + strings.Join(msgs, "; ")
+}
+
+// verify reads the data back from the device and compares the given
+// fields against the values that were saved. On a mismatch the saved
+// values are kept in s.Data, so they still show as modified.
+func (s *Sb) verify(iface *net.Interface, fields []Field) error {
+	saved := s.Data
+	if err := s.GetData(iface); err != nil {
+		return err
+	}
+
+	var mismatches VerifyError
+	for _, f := range fields {
+		if bytes.Equal(saved.encodeField(f.Offset), s.Data.encodeField(f.Offset)) {
+			continue
+		}
+		expected, _ := saved.Value(f.Name)
+		actual, _ := s.Data.Value(f.Name)
+		mismatches = append(mismatches, Mismatch{Field: f, Expected: expected, Actual: actual})
+	}
+	if len(mismatches) > 0 {
+		s.Data = saved
+		return mismatches
+	}
+	return nil
+}
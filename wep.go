@@ -0,0 +1,91 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+)
+
+// WEP key sizes in bytes
+const (
+	WEPKeySize64  = 5
+	WEPKeySize128 = 13
+)
+
+// WEPKey is a WEP encryption key. It is displayed and marshalled as
+// hex digits.
+type WEPKey []byte
+
+// ParseWEPKey parses a key of the given size, written either as hex
+// digits or as ASCII characters.
+func ParseWEPKey(s string, size int) (WEPKey, error) {
+	if len(s) == size*2 {
+		if k, err := hex.DecodeString(s); err == nil {
+			return k, nil
+		}
+	}
+	if len(s) == size {
+		return WEPKey(s), nil
+	}
+	return nil, fmt.Errorf("WEP key must be %d hex digits or %d characters", size*2, size)
+}
+
+func (k WEPKey) String() string {
+	return hex.EncodeToString(k)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k WEPKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts a key
+// of either size, written as hex digits or ASCII characters.
+func (k *WEPKey) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*k = nil
+		return nil
+	}
+	key, err := ParseWEPKey(string(text), WEPKeySize128)
+	if err != nil {
+		key, err = ParseWEPKey(string(text), WEPKeySize64)
+	}
+	if err != nil {
+		return fmt.Errorf("WEP key must be %d or %d hex digits, or %d or %d characters",
+			WEPKeySize64*2, WEPKeySize128*2, WEPKeySize64, WEPKeySize128)
+	}
+	*k = key
+	return nil
+}
+
+// Trim returns the key cut to size bytes, if the bytes beyond it are
+// only the zero padding added by the device.
+func (k WEPKey) Trim(size int) WEPKey {
+	if len(k) > size && len(bytes.TrimRight(k[size:], "\x00")) == 0 {
+		return k[:size]
+	}
+	return k
+}
+
+// IsZero reports whether the key is empty or all zero bytes.
+func (k WEPKey) IsZero() bool {
+	return len(bytes.TrimRight(k, "\x00")) == 0
+}
+
+// WEPKeySize returns the size in bytes of the WEP keys selected by
+// WirelessKeylen.
+func (d *DeviceData) WEPKeySize() int {
+	if d.WirelessKeylen == 1 {
+		return WEPKeySize128
+	}
+	return WEPKeySize64
+}
+
+// wepKeys returns the four WEP keys of d.
+func (d *DeviceData) wepKeys() []WEPKey {
+	return []WEPKey{d.WirelessWEPKey0, d.WirelessWEPKey1, d.WirelessWEPKey2, d.WirelessWEPKey3}
+}
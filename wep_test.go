@@ -0,0 +1,98 @@
+// Copyright 2020 John Crummy. All rights reserved.
+// Use of this source code is governed by an MIT-style license
+// that can be found in the LICENSE file.
+
+package gosqueeze
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseWEPKey(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want WEPKey
+		ok   bool
+	}{
+		{"64-bit hex", "0102030405", WEPKeySize64, WEPKey{1, 2, 3, 4, 5}, true},
+		{"64-bit ascii", "abcde", WEPKeySize64, WEPKey("abcde"), true},
+		{"128-bit hex", "000102030405060708090a0b0c", WEPKeySize128, WEPKey{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, true},
+		{"128-bit ascii", "0123456789abc", WEPKeySize128, WEPKey("0123456789abc"), true},
+		{"hex upper case", "DEADBEEF01", WEPKeySize64, WEPKey{0xde, 0xad, 0xbe, 0xef, 0x01}, true},
+		{"not hex", "zzzzzzzzzz", WEPKeySize64, nil, false},
+		{"too short", "abcd", WEPKeySize64, nil, false},
+		{"128-bit key for 64-bit size", "0123456789abc", WEPKeySize64, nil, false},
+		{"64-bit key for 128-bit size", "abcde", WEPKeySize128, nil, false},
+		{"empty", "", WEPKeySize64, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWEPKey(tt.text, tt.size)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ParseWEPKey(%q, %d) error = %v, want ok %v", tt.text, tt.size, err, tt.ok)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("ParseWEPKey(%q, %d) = %x, want %x", tt.text, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWEPKeyText(t *testing.T) {
+	key := WEPKey("abcde")
+	text, err := key.MarshalText()
+	if err != nil || string(text) != "6162636465" {
+		t.Fatalf("MarshalText() = %s, %v", text, err)
+	}
+	var got WEPKey
+	if err := got.UnmarshalText(text); err != nil || !bytes.Equal(got, key) {
+		t.Errorf("UnmarshalText(%s) = %x, %v", text, got, err)
+	}
+	if err := got.UnmarshalText(nil); err != nil || got != nil {
+		t.Errorf("UnmarshalText(nil) = %x, %v", got, err)
+	}
+}
+
+func TestWEPKeyTrim(t *testing.T) {
+	padded := WEPKey{1, 2, 3, 4, 5, 0, 0, 0, 0, 0, 0, 0, 0}
+	if got := padded.Trim(WEPKeySize64); !bytes.Equal(got, padded[:5]) {
+		t.Errorf("Trim() = %x", got)
+	}
+	long := WEPKey{1, 2, 3, 4, 5, 6, 0, 0, 0, 0, 0, 0, 0}
+	if got := long.Trim(WEPKeySize64); !bytes.Equal(got, long) {
+		t.Errorf("Trim() cut key bytes: %x", got)
+	}
+}
+
+func TestSetWEPKey(t *testing.T) {
+	d := DeviceData{WirelessKeylen: 1}
+	if err := d.Set("WirelessWEPKey1", "0123456789abc"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d.WirelessWEPKey1, WEPKey("0123456789abc")) {
+		t.Errorf("WirelessWEPKey1 = %x", d.WirelessWEPKey1)
+	}
+	if err := d.Set("WirelessWEPKey1", "abcde"); err == nil {
+		t.Error("Set accepted a 64-bit key with 128-bit keys selected")
+	}
+}
+
+func TestValidateWEPKeySize(t *testing.T) {
+	d := DeviceData{
+		LanIPMode:       true,
+		WirelessRegion:  4,
+		WirelessKeylen:  1,
+		WirelessWEPOn:   true,
+		WirelessWEPKey0: WEPKey("abcde"), // 64-bit key with 128-bit keys selected
+	}
+	if err := d.Validate(); err == nil {
+		t.Error("Validate accepted a key of the wrong size")
+	}
+	d.WirelessKeylen = 0
+	if err := d.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}